@@ -0,0 +1,109 @@
+package aleoOracleEncoding
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrOptionalFieldsBodyAndCommitment        = errors.New("request body and request body commitment cannot be used together")
+	ErrDecodingOptionalsInvalidBodyCommitment = errors.New("invalid encoding of the request body commitment")
+	ErrRequestBodyMissing                     = errors.New("encoded optional fields don't contain a request body")
+	ErrRequestBodyMismatch                    = errors.New("request body doesn't match the encoded request body")
+)
+
+const (
+	OPTIONAL_FIELDS_HEADER_HAS_BODY_COMMITMENT = 16 // bit flag used for encoding the request body as a commitment for Aleo, set together with OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY
+
+	// name of the request body commitment in OptionalFieldMap, the value is RequestBodyCommitment
+	OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT = "requestBodyCommitment"
+)
+
+// number of blocks of the request body commitment after the length block
+const bodyCommitmentDigestBlocks = sha256.Size / TARGET_ALIGNMENT
+
+// RequestBodyCommitment replaces a request body with its length and SHA-256 digest, so large bodies take 3 blocks.
+type RequestBodyCommitment struct {
+	Length uint64
+	Digest [sha256.Size]byte
+}
+
+// NewRequestBodyCommitment computes the commitment of a request body
+func NewRequestBodyCommitment(body string) RequestBodyCommitment {
+	return RequestBodyCommitment{
+		Length: uint64(len(body)),
+		Digest: sha256.Sum256([]byte(body)),
+	}
+}
+
+// Verify reports whether the body matches the commitment
+func (c RequestBodyCommitment) Verify(body string) bool {
+	candidate := NewRequestBodyCommitment(body)
+	return c.Length == candidate.Length && subtle.ConstantTimeCompare(c.Digest[:], candidate.Digest[:]) == 1
+}
+
+func (c RequestBodyCommitment) String() string {
+	return fmt.Sprintf("%d bytes, sha256 %s", c.Length, hex.EncodeToString(c.Digest[:]))
+}
+
+// encodes the request body section of the optional fields with the commitment - 1 block with the body length in the first 8 bytes
+// followed by 2 blocks of the SHA-256 digest
+func encodeBodyCommitment(commitment RequestBodyCommitment) []byte {
+	buf := make([]byte, TARGET_ALIGNMENT, TARGET_ALIGNMENT+sha256.Size)
+	copy(buf, NumberToBytes(commitment.Length))
+	return append(buf, commitment.Digest[:]...)
+}
+
+// decodes the request body commitment section starting at offset, returns the commitment and the offset after the section
+func decodeBodyCommitment(buf []byte, offset int) (RequestBodyCommitment, int, error) {
+	end := offset + (1+bodyCommitmentDigestBlocks)*TARGET_ALIGNMENT
+	if end > len(buf) {
+		return RequestBodyCommitment{}, 0, ErrDecodingOptionalsInvalidBodyCommitment
+	}
+
+	commitment := RequestBodyCommitment{
+		Length: BytesToNumber(buf[offset : offset+TARGET_ALIGNMENT/2]),
+	}
+	copy(commitment.Digest[:], buf[offset+TARGET_ALIGNMENT:end])
+
+	return commitment, end, nil
+}
+
+// VerifyRequestBody checks a candidate request body against encoded optional fields. If the body was encoded as a commitment,
+// the length and the digest of the candidate are compared with the commitment, otherwise the candidate is compared with the encoded body.
+// If the encoded body was canonicalized, the candidate is canonicalized with CanonicalizeJSON before the comparison.
+// Returns ErrRequestBodyMissing if there's no request body in the optional fields, ErrRequestBodyMismatch if the body doesn't match.
+func VerifyRequestBody(optionalFields []byte, body string) error {
+	fields, err := DecodeOptionalFieldMap(optionalFields)
+	if err != nil {
+		return err
+	}
+
+	if fields[OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY] == true {
+		canonical, err := CanonicalizeJSON([]byte(body))
+		if err != nil {
+			return ErrRequestBodyMismatch
+		}
+		body = string(canonical)
+	}
+
+	if commitment, ok := fields[OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT].(RequestBodyCommitment); ok {
+		if !commitment.Verify(body) {
+			return ErrRequestBodyMismatch
+		}
+		return nil
+	}
+
+	encodedBody, ok := fields[OPTIONAL_FIELD_REQUEST_BODY].(string)
+	if !ok {
+		return ErrRequestBodyMissing
+	}
+	if encodedBody != body {
+		return ErrRequestBodyMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package aleoOracleEncoding
+
+import (
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRequestBodyCommitment(t *testing.T) {
+	body := strings.Repeat(`{"jsonrpc":"2.0","method":"eth_call"}`, 100)
+	commitment := NewRequestBodyCommitment(body)
+
+	if commitment.Length != uint64(len(body)) || commitment.Digest != sha256.Sum256([]byte(body)) {
+		t.Fatalf("NewRequestBodyCommitment() = %v", commitment)
+	}
+	if !commitment.Verify(body) {
+		t.Error("RequestBodyCommitment.Verify() = false for the original body")
+	}
+	if commitment.Verify(body + " ") {
+		t.Error("RequestBodyCommitment.Verify() = true for a different body")
+	}
+
+	fields := OptionalFields{RequestBody: &body}
+	if err := fields.CommitRequestBody(); err != nil {
+		t.Fatalf("OptionalFields.CommitRequestBody() error = %v", err)
+	}
+	if fields.RequestBody != nil || fields.RequestBodyCommitment == nil || *fields.RequestBodyCommitment != commitment {
+		t.Fatalf("OptionalFields.CommitRequestBody() = %+v", fields)
+	}
+
+	encoded, err := fields.Encode()
+	if err != nil {
+		t.Fatalf("OptionalFields.Encode() error = %v", err)
+	}
+
+	// header, HTML result type, content type, body length and 2 blocks of the digest
+	if len(encoded) != 6*TARGET_ALIGNMENT {
+		t.Fatalf("OptionalFields.Encode() encoded %d blocks, want 6", len(encoded)/TARGET_ALIGNMENT)
+	}
+	wantHeader := []byte{OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY | OPTIONAL_FIELDS_HEADER_HAS_BODY_COMMITMENT, 0, 0, 0, 0, 0, 0, 0, 5, 0, 0, 0, 0, 0, 0, 0}
+	if !reflect.DeepEqual(encoded[:TARGET_ALIGNMENT], wantHeader) {
+		t.Errorf("OptionalFields.Encode() header = %v, want %v", encoded[:TARGET_ALIGNMENT], wantHeader)
+	}
+	if got := BytesToNumber(encoded[3*TARGET_ALIGNMENT : 3*TARGET_ALIGNMENT+8]); got != uint64(len(body)) {
+		t.Errorf("OptionalFields.Encode() body length = %d, want %d", got, len(body))
+	}
+
+	var decoded OptionalFields
+	if err := decoded.Decode(encoded); err != nil {
+		t.Fatalf("OptionalFields.Decode() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, fields) {
+		t.Errorf("OptionalFields.Decode() = %+v, want %+v", decoded, fields)
+	}
+
+	t.Run("legacy decoder", func(t *testing.T) {
+		// the digest of a 32-byte body has the same layout as the body
+		shortBody := strings.Repeat("a", sha256.Size)
+		fields := OptionalFields{RequestBody: &shortBody}
+		if err := fields.CommitRequestBody(); err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := fields.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		htmlResultType, contentType, requestBody, err := DecodeOptionalFields(encoded)
+		if !errors.Is(err, ErrDecodingOptionalsUnsupportedFlags) || htmlResultType != nil || contentType != nil || requestBody != nil {
+			t.Errorf("DecodeOptionalFields() = %v, %v, %v, %v, want %v", htmlResultType, contentType, requestBody, err, ErrDecodingOptionalsUnsupportedFlags)
+		}
+	})
+
+	t.Run("with extensions", func(t *testing.T) {
+		fields := OptionalFieldMap{
+			OPTIONAL_FIELD_REQUEST_CONTENT_TYPE:    "application/json",
+			OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT: commitment,
+			OPTIONAL_FIELD_TIMEOUT:                 uint64(100),
+		}
+
+		encoded, err := EncodeOptionalFieldMap(fields)
+		if err != nil {
+			t.Fatalf("EncodeOptionalFieldMap() error = %v", err)
+		}
+		decoded, err := DecodeOptionalFieldMap(encoded)
+		if err != nil {
+			t.Fatalf("DecodeOptionalFieldMap() error = %v", err)
+		}
+		if !reflect.DeepEqual(decoded, fields) {
+			t.Errorf("DecodeOptionalFieldMap() = %v, want %v", decoded, fields)
+		}
+	})
+
+	t.Run("body and commitment", func(t *testing.T) {
+		fields := OptionalFields{RequestBody: &body, RequestBodyCommitment: &commitment}
+		if _, err := fields.Encode(); !errors.Is(err, ErrOptionalFieldsBodyAndCommitment) {
+			t.Errorf("OptionalFields.Encode() error = %v, want %v", err, ErrOptionalFieldsBodyAndCommitment)
+		}
+		if err := fields.Validate("POST", RESPONSE_FORMAT_JSON); !errors.Is(err, ErrOptionalFieldsBodyAndCommitment) {
+			t.Errorf("OptionalFields.Validate() error = %v, want %v", err, ErrOptionalFieldsBodyAndCommitment)
+		}
+	})
+
+	t.Run("commitment with GET", func(t *testing.T) {
+		fields := OptionalFields{RequestBodyCommitment: &commitment}
+		if err := fields.Validate("GET", RESPONSE_FORMAT_JSON); !errors.Is(err, ErrOptionalFieldsPostOnly) {
+			t.Errorf("OptionalFields.Validate() error = %v, want %v", err, ErrOptionalFieldsPostOnly)
+		}
+	})
+
+	t.Run("commitment flag without body flag", func(t *testing.T) {
+		invalid := append([]byte{}, encoded...)
+		invalid[0] &^= OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY
+		if _, err := DecodeOptionalFieldMap(invalid); !errors.Is(err, ErrDecodingOptionalsInvalidBodyCommitment) {
+			t.Errorf("DecodeOptionalFieldMap() error = %v, want %v", err, ErrDecodingOptionalsInvalidBodyCommitment)
+		}
+	})
+}
+
+func TestVerifyRequestBody(t *testing.T) {
+	body := `{"query":"price"}`
+	commitment := NewRequestBodyCommitment(body)
+
+	committed, err := EncodeOptionalFieldMap(OptionalFieldMap{OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT: commitment})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inline, err := EncodeOptionalFields(nil, nil, &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty, err := EncodeOptionalFields(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		optionalFields []byte
+		body           string
+		wantErr        error
+	}{
+		{name: "commitment", optionalFields: committed, body: body},
+		{name: "commitment mismatch", optionalFields: committed, body: `{"query": "price"}`, wantErr: ErrRequestBodyMismatch},
+		{name: "inline", optionalFields: inline, body: body},
+		{name: "inline mismatch", optionalFields: inline, body: "", wantErr: ErrRequestBodyMismatch},
+		{name: "no body", optionalFields: empty, body: body, wantErr: ErrRequestBodyMissing},
+		{name: "invalid optional fields", optionalFields: committed[:TARGET_ALIGNMENT], body: body, wantErr: ErrDecodingBufferTooShort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyRequestBody(tt.optionalFields, tt.body); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyRequestBody() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+// Command aleo-oracle-encoding encodes, decodes and inspects Aleo oracle data.
+//
+// Usage:
+//
+//	aleo-oracle-encoding encode [-in file] [-format hex|base64|leo]
+//	aleo-oracle-encoding decode [-in file] [-input auto|hex|base64|leo]
+//	aleo-oracle-encoding inspect [-in file] [-input auto|hex|base64|leo] [-html]
+//	aleo-oracle-encoding positions [-in file] [-input auto|hex|base64|leo] [-leo]
+//
+// encode reads a JSON report and prints the encoded report. decode reads an encoded report and prints it as JSON.
+// inspect prints an annotated dump of blocks with the component every block belongs to and the kind of every byte. positions prints positional information of
+// every component. The input is read from stdin if -in is not provided.
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	aleoOracleEncoding "github.com/zkportal/aleo-oracle-encoding"
+)
+
+var (
+	errUnknownCommand = errors.New("unknown command")
+	errUnknownFormat  = errors.New("unknown format")
+	errInvalidInput   = errors.New("input is not a valid hex, base64 or Leo struct")
+)
+
+const (
+	formatAuto   = "auto"
+	formatHex    = "hex"
+	formatBase64 = "base64"
+	formatLeo    = "leo"
+)
+
+const usage = `Usage: aleo-oracle-encoding <command> [flags]
+
+Commands:
+  encode     encode a JSON report
+  decode     decode an encoded report to JSON
+  inspect    print an annotated table of blocks
+  positions  print positional information of all components
+
+Run "aleo-oracle-encoding <command> -h" for command flags.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errUnknownCommand
+	}
+
+	command, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	inputFile := flags.String("in", "", "input file, stdin if not provided")
+
+	switch command {
+	case "encode":
+		format := flags.String("format", formatHex, "output format: hex, base64 or leo")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		input, err := readInput(*inputFile, stdin)
+		if err != nil {
+			return err
+		}
+		return encodeCommand(input, *format, stdout)
+
+	case "decode", "inspect", "positions":
+		inputFormat := flags.String("input", formatAuto, "input format: auto, hex, base64 or leo")
+		var leoPaths, htmlOutput *bool
+		switch command {
+		case "positions":
+			leoPaths = flags.Bool("leo", false, "print Leo struct field paths of every component")
+		case "inspect":
+			htmlOutput = flags.Bool("html", false, "print the annotated dump as an HTML table")
+		}
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		input, err := readInput(*inputFile, stdin)
+		if err != nil {
+			return err
+		}
+		buf, err := parseEncoded(input, *inputFormat)
+		if err != nil {
+			return err
+		}
+
+		switch command {
+		case "decode":
+			return decodeCommand(buf, stdout)
+		case "inspect":
+			return inspectCommand(buf, *htmlOutput, stdout)
+		default:
+			return positionsCommand(buf, *leoPaths, stdout)
+		}
+
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stderr, usage)
+		return nil
+
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("%w: %s", errUnknownCommand, command)
+	}
+}
+
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "" || path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// parses encoded data in the given format. Auto format detects a Leo struct by the opening brace, then tries hex and base64
+func parseEncoded(input []byte, format string) ([]byte, error) {
+	text := strings.TrimSpace(string(input))
+
+	parseHex := func() ([]byte, error) {
+		cleaned := strings.TrimPrefix(strings.Join(strings.Fields(text), ""), "0x")
+		return hex.DecodeString(cleaned)
+	}
+
+	switch format {
+	case formatHex:
+		return parseHex()
+	case formatBase64:
+		return base64.StdEncoding.DecodeString(text)
+	case formatLeo:
+		return aleoOracleEncoding.ParseLeoStruct(text)
+	case formatAuto:
+		if strings.HasPrefix(text, "{") {
+			return aleoOracleEncoding.ParseLeoStruct(text)
+		}
+		if buf, err := parseHex(); err == nil {
+			return buf, nil
+		}
+		if buf, err := base64.StdEncoding.DecodeString(text); err == nil {
+			return buf, nil
+		}
+		return nil, errInvalidInput
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownFormat, format)
+	}
+}
+
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func encodeCommand(input []byte, format string, stdout io.Writer) error {
+	report := new(Report)
+	decoder := json.NewDecoder(strings.NewReader(string(input)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(report); err != nil {
+		return fmt.Errorf("cannot parse report: %w", err)
+	}
+
+	encoded, _, err := encodeReport(report)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case formatHex:
+		_, err = fmt.Fprintln(stdout, hex.EncodeToString(encoded))
+	case formatBase64:
+		_, err = fmt.Fprintln(stdout, base64.StdEncoding.EncodeToString(encoded))
+	case formatLeo:
+		var formatted string
+		formatted, err = aleoOracleEncoding.FormatLeoStruct(encoded)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, formatted)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownFormat, format)
+	}
+
+	return err
+}
+
+func decodeCommand(buf []byte, stdout io.Writer) error {
+	report, _, err := decodeReport(buf)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(stdout, report)
+}
+
+func positionsCommand(buf []byte, leoPaths bool, stdout io.Writer) error {
+	_, positions, err := aleoOracleEncoding.LocateComponents(buf)
+	if err != nil {
+		return err
+	}
+
+	if !leoPaths {
+		return writeJSON(stdout, positions)
+	}
+
+	paths, err := positions.LeoPaths()
+	if err != nil {
+		return err
+	}
+	return writeJSON(stdout, paths)
+}
+
+func inspectCommand(buf []byte, htmlOutput bool, stdout io.Writer) error {
+	dump := aleoOracleEncoding.DumpText
+	if htmlOutput {
+		dump = aleoOracleEncoding.DumpHTML
+	}
+
+	output, err := dump(buf)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(stdout, output)
+	return err
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const exampleReport = `{
+	"attestationData": "12.50",
+	"timestamp": 1700000000,
+	"statusCode": 200,
+	"requestMethod": "POST",
+	"responseFormat": "html",
+	"url": "example.com/some/long/path?query=value",
+	"selector": "/html/body/div/span",
+	"encodingOptions": {"value": "float", "precision": 2},
+	"requestHeaders": {"Accept": "*/*", "User-Agent": "test"},
+	"htmlResultType": "value",
+	"requestContentType": "application/json",
+	"requestBody": "{\"query\": \"price\"}"
+}`
+
+func runCommand(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestEncodeDecode(t *testing.T) {
+	var want Report
+	if err := json.Unmarshal([]byte(exampleReport), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{formatHex, formatBase64, formatLeo} {
+		t.Run(format, func(t *testing.T) {
+			encoded, err := runCommand(t, exampleReport, "encode", "-format", format)
+			if err != nil {
+				t.Fatalf("encode error = %v", err)
+			}
+
+			for _, inputFormat := range []string{formatAuto, format} {
+				decoded, err := runCommand(t, encoded, "decode", "-input", inputFormat)
+				if err != nil {
+					t.Fatalf("decode -input %s error = %v", inputFormat, err)
+				}
+
+				var got Report
+				if err := json.Unmarshal([]byte(decoded), &got); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("decode -input %s = %+v, want %+v", inputFormat, got, want)
+				}
+			}
+		})
+	}
+}
+
+// the example report in the README must be accepted by encode
+func TestReadmeExample(t *testing.T) {
+	readme, err := os.ReadFile("../../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, section, found := strings.Cut(string(readme), "## Command-line tool")
+	if !found {
+		t.Fatal("README doesn't have the command-line tool section")
+	}
+	_, example, found := strings.Cut(section, "```json\n")
+	if !found {
+		t.Fatal("README doesn't have the example report")
+	}
+	example, _, _ = strings.Cut(example, "```")
+
+	encoded, err := runCommand(t, example, "encode")
+	if err != nil {
+		t.Fatalf("encode error = %v", err)
+	}
+	if _, err := runCommand(t, encoded, "decode"); err != nil {
+		t.Errorf("decode error = %v", err)
+	}
+}
+
+func TestCompactMethod(t *testing.T) {
+	stdin := strings.Replace(exampleReport, `"requestMethod": "POST",`, `"requestMethod": "POST", "compactMethod": true,`, 1)
+	encoded, err := runCommand(t, stdin, "encode")
+	if err != nil {
+		t.Fatalf("encode error = %v", err)
+	}
+
+	decoded, err := runCommand(t, encoded, "decode")
+	if err != nil {
+		t.Fatalf("decode error = %v", err)
+	}
+
+	var got Report
+	if err := json.Unmarshal([]byte(decoded), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.RequestMethod != "POST" || !got.CompactMethod {
+		t.Errorf("decode = %+v, want a compact POST method", got)
+	}
+}
+
+func TestInspectAndPositions(t *testing.T) {
+	encoded, err := runCommand(t, exampleReport, "encode")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := runCommand(t, encoded, "inspect")
+	if err != nil {
+		t.Fatalf("inspect error = %v", err)
+	}
+	for _, want := range []string{"BLOCK", "c0.f0", "metaHeader", "c0.f2", "data", "e2 04 00 00", "1250", "optionalFields"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("inspect output doesn't contain %q:\n%s", want, table)
+		}
+	}
+
+	htmlTable, err := runCommand(t, encoded, "inspect", "-html")
+	if err != nil {
+		t.Fatalf("inspect -html error = %v", err)
+	}
+	if !strings.Contains(htmlTable, `<table class="aleo-oracle-dump">`) {
+		t.Errorf("inspect -html output is not an HTML table:\n%s", htmlTable)
+	}
+
+	positions, err := runCommand(t, encoded, "positions")
+	if err != nil {
+		t.Fatalf("positions error = %v", err)
+	}
+	var parsedPositions map[string]map[string]int
+	if err := json.Unmarshal([]byte(positions), &parsedPositions); err != nil {
+		t.Fatal(err)
+	}
+	if parsedPositions["data"]["Pos"] != 2 || parsedPositions["url"]["Len"] != 3 {
+		t.Errorf("positions = %v, want data at block 2 and 3 blocks of URL", parsedPositions)
+	}
+
+	leoPaths, err := runCommand(t, encoded, "positions", "-leo")
+	if err != nil {
+		t.Fatalf("positions -leo error = %v", err)
+	}
+	if !strings.Contains(leoPaths, `"c0.f2"`) {
+		t.Errorf("positions -leo output doesn't contain data path:\n%s", leoPaths)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+	}{
+		{name: "no command", args: nil},
+		{name: "unknown command", args: []string{"foo"}},
+		{name: "invalid report", stdin: "{", args: []string{"encode"}},
+		{name: "unknown report field", stdin: `{"foo": 1}`, args: []string{"encode"}},
+		{name: "invalid report value", stdin: `{"responseFormat": "yaml", "encodingOptions": {"value": "string"}}`, args: []string{"encode"}},
+		{name: "request body without POST", stdin: `{"requestMethod": "GET", "statusCode": 200, "responseFormat": "json", "encodingOptions": {"value": "string"}, "requestBody": "{}"}`, args: []string{"encode"}},
+		{name: "invalid status code", stdin: `{"statusCode": 42, "responseFormat": "json", "encodingOptions": {"value": "string"}}`, args: []string{"encode"}},
+		{name: "compact empty method", stdin: `{"compactMethod": true, "statusCode": 200, "responseFormat": "json", "encodingOptions": {"value": "string"}}`, args: []string{"encode"}},
+		{name: "unknown output format", stdin: exampleReport, args: []string{"encode", "-format", "binary"}},
+		{name: "invalid input", stdin: "not hex!", args: []string{"decode"}},
+		{name: "too short", stdin: "00", args: []string{"decode", "-input", "hex"}},
+		{name: "unknown flag", args: []string{"inspect", "-foo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runCommand(t, tt.stdin, tt.args...); err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+}
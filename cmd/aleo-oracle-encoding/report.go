@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	aleoOracleEncoding "github.com/zkportal/aleo-oracle-encoding"
+	"github.com/zkportal/aleo-oracle-encoding/positionRecorder"
+)
+
+var (
+	errComponentTooLong = errors.New("component is too long to be encoded in the meta header")
+)
+
+// Report contains all the data points encoded by the oracle. JSON field names match the Aleo oracle SDK.
+type Report struct {
+	AttestationData string                             `json:"attestationData"`
+	Timestamp       uint64                             `json:"timestamp"`
+	StatusCode      uint64                             `json:"statusCode"`
+	RequestMethod   string                             `json:"requestMethod"`
+	ResponseFormat  string                             `json:"responseFormat"`
+	Url             string                             `json:"url"`
+	Selector        string                             `json:"selector"`
+	EncodingOptions aleoOracleEncoding.EncodingOptions `json:"encodingOptions"`
+	RequestHeaders  map[string]string                  `json:"requestHeaders"`
+	// encodes a standard request method as a method code, custom methods are always encoded as strings
+	CompactMethod bool `json:"compactMethod,omitempty"`
+
+	aleoOracleEncoding.OptionalFields
+}
+
+func checkedLen(name string, buf []byte) (uint16, error) {
+	if len(buf) > math.MaxUint16 {
+		return 0, fmt.Errorf("%w: %s", errComponentTooLong, name)
+	}
+	return uint16(len(buf)), nil
+}
+
+// encodeReport encodes all components of the report in the canonical layout - the meta header followed by the components
+// in the order of their lengths in the meta header.
+func encodeReport(report *Report) ([]byte, *aleoOracleEncoding.ProofPositionalInfo, error) {
+	if report.RequestHeaders == nil {
+		report.RequestHeaders = make(map[string]string)
+	}
+
+	data, err := aleoOracleEncoding.EncodeAttestationData(report.AttestationData, &report.EncodingOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_DATA, err)
+	}
+
+	responseFormat, err := aleoOracleEncoding.EncodeResponseFormat(report.ResponseFormat)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_RESPONSE_FORMAT, err)
+	}
+
+	timestamp, err := aleoOracleEncoding.EncodeTimestamp(time.Unix(int64(report.Timestamp), 0), aleoOracleEncoding.TIMESTAMP_UNIT_SECONDS)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_TIMESTAMP, err)
+	}
+
+	statusCode, err := aleoOracleEncoding.EncodeStatusCode(int(report.StatusCode))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_STATUS_CODE, err)
+	}
+
+	encodingOptions, err := aleoOracleEncoding.EncodeEncodingOptions(&report.EncodingOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_ENCODING_OPTIONS, err)
+	}
+
+	headers := aleoOracleEncoding.EncodeHeaders(report.RequestHeaders)
+
+	method := []byte(report.RequestMethod)
+	compactMethod := false
+	if report.CompactMethod {
+		method, compactMethod, err = aleoOracleEncoding.EncodeMethod(report.RequestMethod)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_METHOD, err)
+		}
+	}
+
+	if err := report.OptionalFields.Validate(report.RequestMethod, report.ResponseFormat); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_OPTIONAL_FIELDS, err)
+	}
+
+	optionalFields, err := report.OptionalFields.Encode()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_OPTIONAL_FIELDS, err)
+	}
+
+	components := []struct {
+		name string
+		data []byte
+	}{
+		{aleoOracleEncoding.COMPONENT_DATA, data},
+		{aleoOracleEncoding.COMPONENT_TIMESTAMP, timestamp},
+		{aleoOracleEncoding.COMPONENT_STATUS_CODE, statusCode},
+		{aleoOracleEncoding.COMPONENT_METHOD, method},
+		{aleoOracleEncoding.COMPONENT_RESPONSE_FORMAT, responseFormat},
+		{aleoOracleEncoding.COMPONENT_URL, []byte(report.Url)},
+		{aleoOracleEncoding.COMPONENT_SELECTOR, []byte(report.Selector)},
+		{aleoOracleEncoding.COMPONENT_ENCODING_OPTIONS, encodingOptions},
+		{aleoOracleEncoding.COMPONENT_REQUEST_HEADERS, headers},
+		{aleoOracleEncoding.COMPONENT_OPTIONAL_FIELDS, optionalFields},
+	}
+
+	// the meta header encodes the length of the original attestation data string
+	lengths := make(map[string]uint16)
+	for _, component := range components {
+		source := component.data
+		if component.name == aleoOracleEncoding.COMPONENT_DATA {
+			source = []byte(report.AttestationData)
+		}
+
+		lengths[component.name], err = checkedLen(component.name, source)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	metaHeader := make([]byte, aleoOracleEncoding.TARGET_ALIGNMENT*2)
+	err = aleoOracleEncoding.CreateMetaHeader(
+		metaHeader,
+		lengths[aleoOracleEncoding.COMPONENT_DATA],
+		lengths[aleoOracleEncoding.COMPONENT_METHOD],
+		lengths[aleoOracleEncoding.COMPONENT_URL],
+		lengths[aleoOracleEncoding.COMPONENT_SELECTOR],
+		lengths[aleoOracleEncoding.COMPONENT_REQUEST_HEADERS],
+		lengths[aleoOracleEncoding.COMPONENT_OPTIONAL_FIELDS],
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if compactMethod {
+		if err := aleoOracleEncoding.SetMetaHeaderFlags(metaHeader, aleoOracleEncoding.META_HEADER_FLAG_COMPACT_METHOD); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	recorder := positionRecorder.NewPositionRecorder(&buf, aleoOracleEncoding.TARGET_ALIGNMENT)
+
+	if _, err := recorder.WriteLabeled(aleoOracleEncoding.COMPONENT_META_HEADER, metaHeader); err != nil {
+		return nil, nil, err
+	}
+
+	for _, component := range components {
+		recorder.BeginSpan(component.name)
+		if _, err := aleoOracleEncoding.WriteWithPadding(recorder, component.data); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", component.name, err)
+		}
+		if _, err := recorder.EndSpan(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	positions, err := aleoOracleEncoding.ProofPositionalInfoFromSegments(recorder.Spans())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if recorder.TotalBlocks() > aleoOracleEncoding.LEO_MAX_BLOCKS {
+		return nil, nil, fmt.Errorf("%w: encoded report takes %d blocks", aleoOracleEncoding.ErrLeoStructInvalidLength, recorder.TotalBlocks())
+	}
+
+	return buf.Bytes(), positions, nil
+}
+
+// decodeReport locates all components of an encoded report using its meta header and decodes them
+func decodeReport(buf []byte) (*Report, *aleoOracleEncoding.ProofPositionalInfo, error) {
+	header, positions, err := aleoOracleEncoding.LocateComponents(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	component := func(info positionRecorder.PositionInfo) []byte {
+		return buf[info.Pos*aleoOracleEncoding.TARGET_ALIGNMENT : (info.Pos+info.Len)*aleoOracleEncoding.TARGET_ALIGNMENT]
+	}
+
+	// strings are padded, the meta header has their original length
+	stringComponent := func(name string, info positionRecorder.PositionInfo, length int) (string, error) {
+		data := component(info)
+		if length > len(data) {
+			return "", fmt.Errorf("%s: %w", name, aleoOracleEncoding.ErrDecodingBufferTooShort)
+		}
+		return string(data[:length]), nil
+	}
+
+	report := new(Report)
+
+	options, err := aleoOracleEncoding.DecodeEncodingOptions(component(positions.EncodingOptions))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_ENCODING_OPTIONS, err)
+	}
+	report.EncodingOptions = *options
+
+	report.AttestationData, err = aleoOracleEncoding.DecodeAttestationData(component(positions.Data), header.AttestationDataLen, options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_DATA, err)
+	}
+
+	timestamp, err := aleoOracleEncoding.DecodeTimestamp(component(positions.Timestamp), aleoOracleEncoding.TIMESTAMP_UNIT_SECONDS)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_TIMESTAMP, err)
+	}
+	report.Timestamp = uint64(timestamp.Unix())
+
+	statusCode, err := aleoOracleEncoding.DecodeStatusCode(component(positions.StatusCode))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_STATUS_CODE, err)
+	}
+	report.StatusCode = uint64(statusCode)
+
+	report.RequestMethod, err = aleoOracleEncoding.DecodeMethod(component(positions.Method), header.MethodLen, header.HasCompactMethod())
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_METHOD, err)
+	}
+	report.CompactMethod = header.HasCompactMethod()
+
+	report.ResponseFormat, err = aleoOracleEncoding.DecodeResponseFormat(component(positions.ResponseFormat))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_RESPONSE_FORMAT, err)
+	}
+
+	report.Url, err = stringComponent(aleoOracleEncoding.COMPONENT_URL, positions.Url, header.UrlLen)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report.Selector, err = stringComponent(aleoOracleEncoding.COMPONENT_SELECTOR, positions.Selector, header.SelectorLen)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report.RequestHeaders, err = aleoOracleEncoding.DecodeHeaders(component(positions.RequestHeaders))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_REQUEST_HEADERS, err)
+	}
+
+	if err := report.OptionalFields.Decode(component(positions.OptionalFields)); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", aleoOracleEncoding.COMPONENT_OPTIONAL_FIELDS, err)
+	}
+
+	return report, &positions, nil
+}
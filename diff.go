@@ -0,0 +1,342 @@
+package aleoOracleEncoding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zkportal/aleo-oracle-encoding/positionRecorder"
+)
+
+// ReportDifference is one difference between two encoded reports found by DiffReports
+type ReportDifference struct {
+	// Name of the component, e.g. COMPONENT_REQUEST_HEADERS or COMPONENT_META_HEADER. Empty for blocks after the last component.
+	Component string `json:"component"`
+	// Index of the block, in which the difference was found, or -1 if the difference is in the decoded value of the component
+	Block   int    `json:"block"`
+	Message string `json:"message"`
+}
+
+func (d ReportDifference) String() string {
+	if d.Component == "" {
+		return d.Message
+	}
+	return d.Component + ": " + d.Message
+}
+
+// splits encoded headers into entries without verifying the padding and the number of headers
+func headerEntries(component []byte) ([]Header, error) {
+	var entries []Header
+
+	offset := TARGET_ALIGNMENT
+	for offset < len(component) {
+		if offset+2 > len(component) {
+			return nil, ErrDecodingHeadersInvalidHeaderLength
+		}
+
+		entryLen := int(binary.LittleEndian.Uint16(component[offset : offset+2]))
+		entryEnd := offset + 2 + entryLen
+		if entryEnd > len(component) {
+			return nil, ErrDecodingHeadersInvalidHeaderLength
+		}
+
+		name, value, found := strings.Cut(string(component[offset+2:entryEnd]), ":")
+		if !found {
+			return nil, ErrDecodingHeadersInvalidHeaderEncoding
+		}
+		entries = append(entries, Header{Name: name, Value: value})
+
+		offset = blocksForLength(entryEnd) * TARGET_ALIGNMENT
+	}
+
+	return entries, nil
+}
+
+func formatOptionalFieldValue(fields OptionalFieldMap, name string) string {
+	value, ok := fields[name]
+	if !ok {
+		return "none"
+	}
+	if str, ok := value.(string); ok {
+		return strconv.Quote(str)
+	}
+	return fmt.Sprint(value)
+}
+
+func formatEncodingOptions(options *EncodingOptions) string {
+	if options.Value == ENCODING_OPTION_FLOAT {
+		return fmt.Sprintf("%s with precision %d", options.Value, options.Precision)
+	}
+	return options.Value
+}
+
+// returns a readable representation of a component value. The component must be located using the meta header of the report.
+func describeComponent(report *annotatedReport, name string, component []byte) (string, error) {
+	header := report.header
+
+	switch name {
+	case COMPONENT_DATA:
+		value, err := DecodeAttestationData(component, header.AttestationDataLen, report.options)
+		if err != nil {
+			return "", err
+		}
+		if report.options.Value == ENCODING_OPTION_STRING {
+			return strconv.Quote(value), nil
+		}
+		return value, nil
+	case COMPONENT_TIMESTAMP, COMPONENT_STATUS_CODE:
+		if len(component) < TARGET_ALIGNMENT {
+			return "", ErrDecodingBufferTooShort
+		}
+		return strconv.FormatUint(BytesToNumber(component[:TARGET_ALIGNMENT/2]), 10), nil
+	case COMPONENT_METHOD:
+		method, err := DecodeMethod(component, header.MethodLen, header.HasCompactMethod())
+		if err != nil {
+			return "", err
+		}
+		return strconv.Quote(method), nil
+	case COMPONENT_URL:
+		return strconv.Quote(string(component[:header.UrlLen])), nil
+	case COMPONENT_SELECTOR:
+		return strconv.Quote(string(component[:header.SelectorLen])), nil
+	case COMPONENT_RESPONSE_FORMAT:
+		return DecodeResponseFormat(component)
+	case COMPONENT_ENCODING_OPTIONS:
+		options, err := DecodeEncodingOptions(component)
+		if err != nil {
+			return "", err
+		}
+		return formatEncodingOptions(options), nil
+	default:
+		return "", ErrValueEncodingUnknown
+	}
+}
+
+// compares decoded values of the meta header
+func diffMetaHeaders(a, b *annotatedReport) []string {
+	var messages []string
+
+	lengthsA, lengthsB := a.header.componentLengths(), b.header.componentLengths()
+	for i, component := range a.positions.Components() {
+		if lengthsA[i] != lengthsB[i] {
+			messages = append(messages, fmt.Sprintf("%s length %d vs %d", component.Name, lengthsA[i], lengthsB[i]))
+		}
+	}
+	if a.header.Flags != b.header.Flags {
+		messages = append(messages, fmt.Sprintf("flags %d vs %d", a.header.Flags, b.header.Flags))
+	}
+
+	return messages
+}
+
+// compares header entries in the encoded order
+func diffHeaders(a, b []byte) []string {
+	entriesA, errA := headerEntries(a)
+	entriesB, errB := headerEntries(b)
+	if errA != nil || errB != nil {
+		return diffDecodingErrors(errA, errB)
+	}
+
+	var messages []string
+
+	for i := 0; i < len(entriesA) || i < len(entriesB); i++ {
+		switch {
+		case i >= len(entriesB):
+			messages = append(messages, fmt.Sprintf("entry %d `%s` is missing in the second report", i, entriesA[i].Name))
+		case i >= len(entriesA):
+			messages = append(messages, fmt.Sprintf("entry %d `%s` is missing in the first report", i, entriesB[i].Name))
+		case entriesA[i].Name != entriesB[i].Name:
+			messages = append(messages, fmt.Sprintf("entry %d is `%s` vs `%s`", i, entriesA[i].Name, entriesB[i].Name))
+		case entriesA[i].Value != entriesB[i].Value:
+			messages = append(messages, fmt.Sprintf("entry %d `%s` differs: %q vs %q", i, entriesA[i].Name, entriesA[i].Value, entriesB[i].Value))
+		}
+	}
+
+	return messages
+}
+
+// compares decoded optional fields including extension fields
+func diffOptionalFields(a, b []byte) []string {
+	fieldsA, errA := DecodeOptionalFieldMap(a)
+	fieldsB, errB := DecodeOptionalFieldMap(b)
+	if errA != nil || errB != nil {
+		return diffDecodingErrors(errA, errB)
+	}
+
+	names := make([]string, 0, len(fieldsA)+len(fieldsB))
+	for name := range fieldsA {
+		names = append(names, name)
+	}
+	for name := range fieldsB {
+		if _, ok := fieldsA[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var messages []string
+	for _, name := range names {
+		valueA, valueB := formatOptionalFieldValue(fieldsA, name), formatOptionalFieldValue(fieldsB, name)
+		if valueA != valueB {
+			messages = append(messages, fmt.Sprintf("%s %s vs %s", name, valueA, valueB))
+		}
+	}
+
+	return messages
+}
+
+// reports a component, which can be decoded only in one of the reports. If both reports cannot be decoded, the difference is left to the byte comparison.
+func diffDecodingErrors(errA, errB error) []string {
+	switch {
+	case errA != nil && errB == nil:
+		return []string{fmt.Sprintf("cannot decode the first report: %v", errA)}
+	case errA == nil && errB != nil:
+		return []string{fmt.Sprintf("cannot decode the second report: %v", errB)}
+	default:
+		return nil
+	}
+}
+
+// compares decoded values of a component
+func diffComponentValues(a, b *annotatedReport, name string, componentA, componentB []byte) []string {
+	switch name {
+	case COMPONENT_META_HEADER:
+		return diffMetaHeaders(a, b)
+	case COMPONENT_REQUEST_HEADERS:
+		return diffHeaders(componentA, componentB)
+	case COMPONENT_OPTIONAL_FIELDS:
+		return diffOptionalFields(componentA, componentB)
+	}
+
+	valueA, errA := describeComponent(a, name, componentA)
+	valueB, errB := describeComponent(b, name, componentB)
+	if errA != nil || errB != nil {
+		return diffDecodingErrors(errA, errB)
+	}
+
+	if valueA != valueB {
+		return []string{fmt.Sprintf("%s vs %s", valueA, valueB)}
+	}
+	return nil
+}
+
+// compares blocks with the same indices byte by byte. Differences in data bytes are reported only if reportData is true.
+func diffBlocks(a, b *annotatedReport, component string, from, to int, reportData bool) []ReportDifference {
+	var differences []ReportDifference
+
+	for i := from; i < to; i++ {
+		blockA, blockB := &a.blocks[i], &b.blocks[i]
+		if bytes.Equal(blockA.Bytes, blockB.Bytes) {
+			continue
+		}
+
+		var differentKinds [BYTE_KIND_RESERVED + 1]bool
+		for j := range blockA.Bytes {
+			if blockA.Bytes[j] == blockB.Bytes[j] {
+				continue
+			}
+			// a byte is padding or reserved only if it has the same meaning in both reports
+			if blockA.Kinds[j] == blockB.Kinds[j] {
+				differentKinds[blockA.Kinds[j]] = true
+			} else {
+				differentKinds[BYTE_KIND_DATA] = true
+			}
+		}
+
+		for kind, different := range differentKinds {
+			if !different || (ByteKind(kind) == BYTE_KIND_DATA && !reportData) {
+				continue
+			}
+			differences = append(differences, ReportDifference{
+				Component: component,
+				Block:     i,
+				Message:   fmt.Sprintf("%s differs in block %d", ByteKind(kind), i),
+			})
+		}
+	}
+
+	return differences
+}
+
+func endOfComponents(positions *ProofPositionalInfo) int {
+	last := positions.OptionalFields
+	return last.Pos + last.Len
+}
+
+// DiffReports compares two encoded reports component by component. The components of both reports are located using their meta headers,
+// see LocateComponents, so the reports don't need to have the same layout.
+//
+// Decoded values of every component are compared first, e.g. "data: 12.50 vs 12.5" or "requestHeaders: entry 3 `User-Agent` differs",
+// header entries are numbered from 0 in the encoded order. If the component has the same position in both reports, the blocks are also compared byte by byte
+// to find differences in padding and reserved bytes, e.g. "padding differs in block 41". Differences in data bytes are reported this way only if the
+// decoded values are the same. Blocks after the last component are compared as padding,
+// the number of such blocks is compared if the components of both reports end in the same block.
+//
+// Returns nil if the reports are identical. Returns an error if the components of either report cannot be located.
+func DiffReports(a, b []byte) ([]ReportDifference, error) {
+	reportA, err := annotateReport(a)
+	if err != nil {
+		return nil, fmt.Errorf("first report: %w", err)
+	}
+	reportB, err := annotateReport(b)
+	if err != nil {
+		return nil, fmt.Errorf("second report: %w", err)
+	}
+
+	var differences []ReportDifference
+
+	componentsB := reportB.components()
+	for i, componentA := range reportA.components() {
+		positionA, positionB := componentA.Position, componentsB[i].Position
+		bytesA, bytesB := componentBytes(a, positionA), componentBytes(b, positionB)
+
+		// the same bytes may be decoded differently, e.g. a float with a different length of the original string in the meta header
+		messages := diffComponentValues(reportA, reportB, componentA.Name, bytesA, bytesB)
+		for _, message := range messages {
+			differences = append(differences, ReportDifference{Component: componentA.Name, Block: -1, Message: message})
+		}
+
+		if bytes.Equal(bytesA, bytesB) {
+			continue
+		}
+
+		if positionA.Pos != positionB.Pos || positionA.Len != positionB.Len {
+			if len(messages) == 0 {
+				differences = append(differences, ReportDifference{
+					Component: componentA.Name,
+					Block:     -1,
+					Message:   fmt.Sprintf("located at blocks %s vs %s", formatBlockRange(positionA), formatBlockRange(positionB)),
+				})
+			}
+			continue
+		}
+
+		differences = append(differences, diffBlocks(reportA, reportB, componentA.Name, positionA.Pos, positionA.Pos+positionA.Len, len(messages) == 0)...)
+	}
+
+	// trailing blocks
+	endA, endB := endOfComponents(&reportA.positions), endOfComponents(&reportB.positions)
+	if endA == endB {
+		if len(reportA.blocks) != len(reportB.blocks) {
+			differences = append(differences, ReportDifference{
+				Block:   -1,
+				Message: fmt.Sprintf("length %d vs %d blocks", len(reportA.blocks), len(reportB.blocks)),
+			})
+		}
+
+		numBlocks := len(reportA.blocks)
+		if len(reportB.blocks) < numBlocks {
+			numBlocks = len(reportB.blocks)
+		}
+		differences = append(differences, diffBlocks(reportA, reportB, "", endA, numBlocks, true)...)
+	}
+
+	return differences, nil
+}
+
+func formatBlockRange(info *positionRecorder.PositionInfo) string {
+	return fmt.Sprintf("%d-%d", info.Pos, info.Pos+info.Len-1)
+}
@@ -0,0 +1,151 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestDiffReports(t *testing.T) {
+	newReport := func() *testReport {
+		return &testReport{
+			data:            "12.50",
+			encodingOptions: EncodingOptions{Value: ENCODING_OPTION_FLOAT, Precision: 2},
+			timestamp:       1700000000,
+			statusCode:      200,
+			method:          "GET",
+			responseFormat:  RESPONSE_FORMAT_JSON,
+			url:             "example.com/price",
+			selector:        "data.price",
+			headers:         map[string]string{"Accept": "*/*", "User-Agent": "node/1"},
+		}
+	}
+
+	base, positions := encodeTestReport(t, newReport())
+
+	body := "{}"
+	tests := []struct {
+		name   string
+		modify func(report *testReport)
+		// modifies the encoded report
+		modifyBytes func(buf []byte) []byte
+		want        []string
+	}{
+		{
+			name: "identical",
+		},
+		{
+			name:   "float with a different length",
+			modify: func(report *testReport) { report.data = "12.5" },
+			want:   []string{"metaHeader: data length 5 vs 4", "data: 12.50 vs 12.5"},
+		},
+		{
+			name:   "header value",
+			modify: func(report *testReport) { report.headers["User-Agent"] = "node/2" },
+			want:   []string{"requestHeaders: entry 1 `User-Agent` differs: \"node/1\" vs \"node/2\""},
+		},
+		{
+			name:   "missing header",
+			modify: func(report *testReport) { delete(report.headers, "User-Agent") },
+			want: []string{
+				"metaHeader: requestHeaders length 64 vs 32",
+				"requestHeaders: entry 1 `User-Agent` is missing in the second report",
+			},
+		},
+		{
+			name: "url with a different length",
+			modify: func(report *testReport) {
+				report.url = "example.com/price/usd"
+				report.timestamp = 1700000001
+			},
+			want: []string{
+				"metaHeader: url length 17 vs 21",
+				"timestamp: 1700000000 vs 1700000001",
+				"url: \"example.com/price\" vs \"example.com/price/usd\"",
+			},
+		},
+		{
+			name:   "optional fields",
+			modify: func(report *testReport) { report.body = &body },
+			want:   []string{"metaHeader: optionalFields length 64 vs 80", "optionalFields: requestBody none vs \"{}\""},
+		},
+		{
+			name:   "compact method",
+			modify: func(report *testReport) { report.method = "POST"; report.compactMethod = true },
+			want:   []string{"metaHeader: method length 3 vs 8", "metaHeader: flags 0 vs 1", "method: \"GET\" vs \"POST\""},
+		},
+		{
+			name: "non-zero padding",
+			modifyBytes: func(buf []byte) []byte {
+				buf[positions.Method.Pos*TARGET_ALIGNMENT+5] = 1
+				return buf
+			},
+			want: []string{"method: padding differs in block " + strconv.Itoa(positions.Method.Pos)},
+		},
+		{
+			name: "non-zero reserved bytes",
+			modifyBytes: func(buf []byte) []byte {
+				buf[positions.StatusCode.Pos*TARGET_ALIGNMENT+15] = 1
+				return buf
+			},
+			want: []string{"statusCode: reserved differs in block " + strconv.Itoa(positions.StatusCode.Pos)},
+		},
+		{
+			name: "trailing blocks",
+			modifyBytes: func(buf []byte) []byte {
+				buf = append(buf, make([]byte, 2*TARGET_ALIGNMENT)...)
+				buf[len(buf)-1] = 1
+				return buf
+			},
+			want: []string{"length " + strconv.Itoa(len(base)/TARGET_ALIGNMENT) + " vs " + strconv.Itoa(len(base)/TARGET_ALIGNMENT+2) + " blocks"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := newReport()
+			if tt.modify != nil {
+				tt.modify(report)
+			}
+			other, _ := encodeTestReport(t, report)
+			if tt.modifyBytes != nil {
+				other = tt.modifyBytes(other)
+			}
+
+			differences, err := DiffReports(base, other)
+			if err != nil {
+				t.Fatalf("DiffReports() error = %v", err)
+			}
+
+			var got []string
+			for _, difference := range differences {
+				got = append(got, difference.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffReports() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("padding of the same length", func(t *testing.T) {
+		a := append(append([]byte{}, base...), make([]byte, 2*TARGET_ALIGNMENT)...)
+		b := append(append([]byte{}, base...), make([]byte, 2*TARGET_ALIGNMENT)...)
+		b[len(b)-1] = 1
+
+		differences, err := DiffReports(a, b)
+		if err != nil {
+			t.Fatalf("DiffReports() error = %v", err)
+		}
+		want := []ReportDifference{{Block: len(b)/TARGET_ALIGNMENT - 1, Message: "padding differs in block " + strconv.Itoa(len(b)/TARGET_ALIGNMENT-1)}}
+		if !reflect.DeepEqual(differences, want) {
+			t.Errorf("DiffReports() = %v, want %v", differences, want)
+		}
+	})
+
+	t.Run("invalid report", func(t *testing.T) {
+		_, err := DiffReports(base, base[:TARGET_ALIGNMENT])
+		if !errors.Is(err, ErrDecodingBufferTooShort) {
+			t.Errorf("DiffReports() error = %v, want %v", err, ErrDecodingBufferTooShort)
+		}
+	})
+}
@@ -0,0 +1,392 @@
+package aleoOracleEncoding
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/zkportal/aleo-oracle-encoding/positionRecorder"
+)
+
+// ByteKind describes the meaning of a byte in the encoded data
+type ByteKind int
+
+const (
+	BYTE_KIND_DATA     ByteKind = iota // the byte is a part of the encoded value
+	BYTE_KIND_PADDING                  // the byte pads the encoded value to TARGET_ALIGNMENT, expected to be 0
+	BYTE_KIND_RESERVED                 // the byte is reserved by the encoding format, expected to be 0
+)
+
+func (k ByteKind) String() string {
+	switch k {
+	case BYTE_KIND_DATA:
+		return "data"
+	case BYTE_KIND_PADDING:
+		return "padding"
+	case BYTE_KIND_RESERVED:
+		return "reserved"
+	default:
+		return "unknown"
+	}
+}
+
+func (k ByteKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// AnnotatedBlock is one block of encoded data annotated with the component it belongs to
+type AnnotatedBlock struct {
+	Index int          `json:"index"`
+	Path  LeoFieldPath `json:"path"`
+	// Name of the component, e.g. COMPONENT_URL or COMPONENT_META_HEADER. Empty for blocks after the last component.
+	Component string `json:"component"`
+	Bytes     []byte `json:"bytes"`
+	// Decimal representation of the block as a little-endian u128 number, the way it appears in the Leo struct
+	U128  string                     `json:"u128"`
+	Kinds [TARGET_ALIGNMENT]ByteKind `json:"kinds"`
+}
+
+// ASCII returns printable data bytes as characters, other data bytes as '.', padding bytes as '_' and reserved bytes as '-'
+func (b *AnnotatedBlock) ASCII() string {
+	var builder strings.Builder
+	for i, char := range b.Bytes {
+		switch {
+		case b.Kinds[i] == BYTE_KIND_PADDING:
+			builder.WriteByte('_')
+		case b.Kinds[i] == BYTE_KIND_RESERVED:
+			builder.WriteByte('-')
+		case char >= 0x20 && char < 0x7f:
+			builder.WriteByte(char)
+		default:
+			builder.WriteByte('.')
+		}
+	}
+	return builder.String()
+}
+
+// classifies every byte of a component, returns one kind per byte
+type byteClassifier func(component []byte) []ByteKind
+
+// all bytes are kind
+func classifyAll(length int, kind ByteKind) []ByteKind {
+	kinds := make([]ByteKind, length)
+	for i := range kinds {
+		kinds[i] = kind
+	}
+	return kinds
+}
+
+// the first dataLen bytes are data, the rest of the bytes are rest
+func classifyPrefix(length, dataLen int, rest ByteKind) []ByteKind {
+	kinds := classifyAll(length, rest)
+	for i := 0; i < dataLen && i < length; i++ {
+		kinds[i] = BYTE_KIND_DATA
+	}
+	return kinds
+}
+
+// classifies a length-prefixed string section of optional fields starting at offset, returns the offset after the section
+func classifyLengthPrefixedString(component []byte, kinds []ByteKind, offset int) int {
+	if offset+TARGET_ALIGNMENT > len(component) {
+		return len(component)
+	}
+
+	copy(kinds[offset:], classifyPrefix(TARGET_ALIGNMENT, TARGET_ALIGNMENT/2, BYTE_KIND_RESERVED))
+	length := BytesToNumber(component[offset : offset+TARGET_ALIGNMENT/2])
+	offset += TARGET_ALIGNMENT
+
+	if length > uint64(len(component)-offset) {
+		return len(component)
+	}
+
+	blocks := blocksForLength(int(length))
+	copy(kinds[offset:], classifyPrefix(blocks*TARGET_ALIGNMENT, int(length), BYTE_KIND_PADDING))
+
+	return offset + blocks*TARGET_ALIGNMENT
+}
+
+func classifyHeaders(component []byte) []ByteKind {
+	kinds := classifyAll(len(component), BYTE_KIND_DATA)
+
+	offset := TARGET_ALIGNMENT
+	for offset+2 <= len(component) {
+		entryLen := int(binary.LittleEndian.Uint16(component[offset : offset+2]))
+		entryEnd := offset + 2 + entryLen
+		if entryEnd > len(component) {
+			break
+		}
+
+		paddedEnd := blocksForLength(entryEnd) * TARGET_ALIGNMENT
+		for i := entryEnd; i < paddedEnd; i++ {
+			kinds[i] = BYTE_KIND_PADDING
+		}
+
+		offset = paddedEnd
+	}
+
+	return kinds
+}
+
+func classifyOptionalFields(component []byte) []ByteKind {
+	kinds := classifyAll(len(component), BYTE_KIND_DATA)
+	if len(component) < 2*TARGET_ALIGNMENT {
+		return kinds
+	}
+
+	// meta header block - bitmask, reserved bytes and the number of blocks
+	copy(kinds[1:TARGET_ALIGNMENT/2], classifyAll(TARGET_ALIGNMENT/2-1, BYTE_KIND_RESERVED))
+
+	// HTML result type, the attribute type is followed by the attribute name
+	copy(kinds[TARGET_ALIGNMENT:], classifyPrefix(TARGET_ALIGNMENT, 1, BYTE_KIND_RESERVED))
+	offset := 2 * TARGET_ALIGNMENT
+	if component[0]&OPTIONAL_FIELDS_HEADER_HAS_HTML_RESULT_TYPE != 0 && component[TARGET_ALIGNMENT] == HTML_RESULT_TYPE_ATTRIBUTE_VALUE {
+		offset = classifyLengthPrefixedString(component, kinds, offset)
+	}
+
+	// request content type and request body
+	offset = classifyLengthPrefixedString(component, kinds, offset)
+	if component[0]&OPTIONAL_FIELDS_HEADER_HAS_BODY_COMMITMENT != 0 {
+		// the length of the body followed by the digest
+		if offset+TARGET_ALIGNMENT <= len(component) {
+			copy(kinds[offset:], classifyPrefix(TARGET_ALIGNMENT, TARGET_ALIGNMENT/2, BYTE_KIND_RESERVED))
+		}
+		offset += (1 + bodyCommitmentDigestBlocks) * TARGET_ALIGNMENT
+	} else {
+		offset = classifyLengthPrefixedString(component, kinds, offset)
+	}
+
+	if component[0]&OPTIONAL_FIELDS_HEADER_HAS_EXTENSIONS != 0 {
+		classifyOptionalFieldExtensions(component, kinds, offset)
+	}
+
+	return kinds
+}
+
+// classifies the extension section of optional fields starting at offset
+func classifyOptionalFieldExtensions(component []byte, kinds []ByteKind, offset int) {
+	if offset+TARGET_ALIGNMENT > len(component) {
+		return
+	}
+
+	// the number of fields
+	copy(kinds[offset:], classifyPrefix(TARGET_ALIGNMENT, TARGET_ALIGNMENT/2, BYTE_KIND_RESERVED))
+	offset += TARGET_ALIGNMENT
+
+	// every field is a block with a tag and a length followed by the padded value
+	for offset+TARGET_ALIGNMENT <= len(component) {
+		length := BytesToNumber(component[offset+TARGET_ALIGNMENT/2 : offset+TARGET_ALIGNMENT])
+		offset += TARGET_ALIGNMENT
+
+		if length > uint64(len(component)-offset) {
+			return
+		}
+
+		blocks := blocksForLength(int(length))
+		copy(kinds[offset:], classifyPrefix(blocks*TARGET_ALIGNMENT, int(length), BYTE_KIND_PADDING))
+		offset += blocks * TARGET_ALIGNMENT
+	}
+}
+
+// returns byte classifiers for all components including the meta header by component name
+func componentClassifiers(header *MetaHeader, options *EncodingOptions) map[string]byteClassifier {
+	stringClassifier := func(length int) byteClassifier {
+		return func(component []byte) []ByteKind {
+			return classifyPrefix(len(component), length, BYTE_KIND_PADDING)
+		}
+	}
+	fixedClassifier := func(length int) byteClassifier {
+		return func(component []byte) []ByteKind {
+			return classifyPrefix(len(component), length, BYTE_KIND_RESERVED)
+		}
+	}
+
+	dataClassifier := stringClassifier(header.AttestationDataLen)
+	if options != nil && options.Value != ENCODING_OPTION_STRING {
+		dataClassifier = fixedClassifier(TARGET_ALIGNMENT / 2)
+	}
+
+	methodClassifier := stringClassifier(header.MethodLen)
+	if header.HasCompactMethod() {
+		methodClassifier = fixedClassifier(header.MethodLen)
+	}
+
+	return map[string]byteClassifier{
+		// 10 lengths of 2 bytes and the flags
+		COMPONENT_META_HEADER: fixedClassifier(META_HEADER_FLAGS_POSITION + 1),
+		COMPONENT_DATA:        dataClassifier,
+		COMPONENT_TIMESTAMP:   fixedClassifier(header.TimestampLen),
+		COMPONENT_STATUS_CODE: fixedClassifier(header.StatusCodeLen),
+		COMPONENT_METHOD:      methodClassifier,
+		// response format is encoded in the first byte
+		COMPONENT_RESPONSE_FORMAT: fixedClassifier(1),
+		COMPONENT_URL:             stringClassifier(header.UrlLen),
+		COMPONENT_SELECTOR:        stringClassifier(header.SelectorLen),
+		COMPONENT_ENCODING_OPTIONS: func(component []byte) []ByteKind {
+			// value type in the first byte, precision in the first byte of the second half
+			kinds := classifyAll(len(component), BYTE_KIND_RESERVED)
+			// the meta header may declare no encoding options
+			if len(component) >= TARGET_ALIGNMENT {
+				kinds[0] = BYTE_KIND_DATA
+				kinds[TARGET_ALIGNMENT/2] = BYTE_KIND_DATA
+			}
+			return kinds
+		},
+		COMPONENT_REQUEST_HEADERS: classifyHeaders,
+		COMPONENT_OPTIONAL_FIELDS: classifyOptionalFields,
+	}
+}
+
+// encoded data with located components and annotated blocks
+type annotatedReport struct {
+	header    *MetaHeader
+	positions ProofPositionalInfo
+	// nil if the encoding options cannot be decoded
+	options *EncodingOptions
+	blocks  []AnnotatedBlock
+}
+
+// returns the meta header and all components in the canonical order
+func (r *annotatedReport) components() []PositionalComponent {
+	return append([]PositionalComponent{{
+		Name:     COMPONENT_META_HEADER,
+		Position: &positionRecorder.PositionInfo{Pos: 0, Len: META_HEADER_BLOCKS},
+	}}, r.positions.Components()...)
+}
+
+func annotateReport(buf []byte) (*annotatedReport, error) {
+	if len(buf) > LEO_MAX_BLOCKS*TARGET_ALIGNMENT {
+		return nil, ErrLeoStructInvalidLength
+	}
+
+	header, positions, err := LocateComponents(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &annotatedReport{
+		header:    header,
+		positions: positions,
+	}
+
+	report.options, err = DecodeEncodingOptions(componentBytes(buf, &positions.EncodingOptions))
+	if err != nil {
+		report.options = nil
+	}
+
+	numBlocks := len(buf) / TARGET_ALIGNMENT
+	report.blocks = make([]AnnotatedBlock, numBlocks)
+	for i := range report.blocks {
+		path, _ := LeoFieldPathOfBlock(i)
+		data := buf[i*TARGET_ALIGNMENT : (i+1)*TARGET_ALIGNMENT]
+
+		report.blocks[i] = AnnotatedBlock{
+			Index: i,
+			Path:  path,
+			Bytes: data,
+			U128:  BlockToU128(data).String(),
+		}
+		copy(report.blocks[i].Kinds[:], classifyAll(TARGET_ALIGNMENT, BYTE_KIND_PADDING))
+	}
+
+	classifiers := componentClassifiers(header, report.options)
+
+	for _, component := range report.components() {
+		kinds := classifiers[component.Name](componentBytes(buf, component.Position))
+
+		for i := 0; i < component.Position.Len; i++ {
+			block := &report.blocks[component.Position.Pos+i]
+			block.Component = component.Name
+			copy(block.Kinds[:], kinds[i*TARGET_ALIGNMENT:(i+1)*TARGET_ALIGNMENT])
+		}
+	}
+
+	return report, nil
+}
+
+// AnnotateBlocks splits encoded data into blocks and annotates every block with its Leo field path, the component it belongs to
+// and the meaning of every byte. The components are located using the meta header, see LocateComponents. Blocks after the last component
+// are annotated as padding.
+func AnnotateBlocks(buf []byte) ([]AnnotatedBlock, error) {
+	report, err := annotateReport(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.blocks, nil
+}
+
+// DumpText renders encoded data as an annotated table of blocks with the block index, Leo field path, component name, hex bytes, ASCII and u128 value.
+// In the ASCII column padding bytes are shown as '_' and reserved bytes as '-'. Non-zero padding and reserved bytes are marked with '!' after the hex bytes.
+func DumpText(buf []byte) (string, error) {
+	blocks, err := AnnotateBlocks(buf)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	table := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "BLOCK\tFIELD\tCOMPONENT\tHEX\tASCII\tU128")
+
+	for i := range blocks {
+		block := &blocks[i]
+
+		hexBytes := make([]string, len(block.Bytes))
+		for j, b := range block.Bytes {
+			hexBytes[j] = hex.EncodeToString([]byte{b})
+			if b != 0 && block.Kinds[j] != BYTE_KIND_DATA {
+				hexBytes[j] += "!"
+			}
+		}
+
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t|%s|\t%s\n", block.Index, block.Path, block.Component, strings.Join(hexBytes, " "), block.ASCII(), block.U128)
+	}
+
+	if err := table.Flush(); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+// DumpHTML renders encoded data as an HTML table with the same columns as DumpText. Every byte is wrapped in a span
+// with a class named after its ByteKind - "data", "padding" or "reserved", non-zero padding and reserved bytes also have a "nonzero" class.
+// The table has an "aleo-oracle-dump" class and doesn't include any styles.
+func DumpHTML(buf []byte) (string, error) {
+	blocks, err := AnnotateBlocks(buf)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("<table class=\"aleo-oracle-dump\">\n")
+	builder.WriteString("<thead><tr><th>Block</th><th>Field</th><th>Component</th><th>Hex</th><th>ASCII</th><th>U128</th></tr></thead>\n<tbody>\n")
+
+	for i := range blocks {
+		block := &blocks[i]
+		ascii := block.ASCII()
+
+		var hexCell, asciiCell strings.Builder
+		for j, b := range block.Bytes {
+			class := block.Kinds[j].String()
+			if b != 0 && block.Kinds[j] != BYTE_KIND_DATA {
+				class += " nonzero"
+			}
+
+			if j != 0 {
+				hexCell.WriteString(" ")
+			}
+			hexCell.WriteString(fmt.Sprintf("<span class=\"%s\">%02x</span>", class, b))
+			asciiCell.WriteString(fmt.Sprintf("<span class=\"%s\">%s</span>", class, html.EscapeString(ascii[j:j+1])))
+		}
+
+		builder.WriteString(fmt.Sprintf("<tr class=\"%s\"><td>%d</td><td>%s</td><td>%s</td><td><code>%s</code></td><td><code>%s</code></td><td>%s</td></tr>\n",
+			html.EscapeString(block.Component), block.Index, block.Path, html.EscapeString(block.Component), hexCell.String(), asciiCell.String(), block.U128))
+	}
+
+	builder.WriteString("</tbody>\n</table>\n")
+
+	return builder.String(), nil
+}
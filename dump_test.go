@@ -0,0 +1,162 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAnnotateBlocks(t *testing.T) {
+	contentType := "text/plain"
+	report := &testReport{
+		data:            "12.5",
+		encodingOptions: EncodingOptions{Value: ENCODING_OPTION_FLOAT, Precision: 1},
+		timestamp:       1700000000,
+		statusCode:      200,
+		method:          "POST",
+		responseFormat:  RESPONSE_FORMAT_JSON,
+		url:             "example.com/path",
+		selector:        "a.b",
+		headers:         map[string]string{"Accept": "*/*"},
+		contentType:     &contentType,
+	}
+	encoded, positions := encodeTestReport(t, report)
+	// trailing blocks of a Leo struct
+	encoded = append(encoded, make([]byte, 2*TARGET_ALIGNMENT)...)
+
+	blocks, err := AnnotateBlocks(encoded)
+	if err != nil {
+		t.Fatalf("AnnotateBlocks() error = %v", err)
+	}
+	if len(blocks) != len(encoded)/TARGET_ALIGNMENT {
+		t.Fatalf("AnnotateBlocks() returned %d blocks, want %d", len(blocks), len(encoded)/TARGET_ALIGNMENT)
+	}
+
+	kindsString := func(block *AnnotatedBlock) string {
+		var builder strings.Builder
+		for _, kind := range block.Kinds {
+			builder.WriteString(kind.String()[:1])
+		}
+		return builder.String()
+	}
+
+	tests := []struct {
+		name          string
+		block         int
+		wantComponent string
+		wantKinds     string
+	}{
+		{name: "meta header", block: 0, wantComponent: COMPONENT_META_HEADER, wantKinds: "dddddddddddddddd"},
+		{name: "meta header flags", block: 1, wantComponent: COMPONENT_META_HEADER, wantKinds: "dddddrrrrrrrrrrr"},
+		{name: "float data", block: positions.Data.Pos, wantComponent: COMPONENT_DATA, wantKinds: "ddddddddrrrrrrrr"},
+		{name: "timestamp", block: positions.Timestamp.Pos, wantComponent: COMPONENT_TIMESTAMP, wantKinds: "ddddddddrrrrrrrr"},
+		{name: "method", block: positions.Method.Pos, wantComponent: COMPONENT_METHOD, wantKinds: "ddddpppppppppppp"},
+		{name: "response format", block: positions.ResponseFormat.Pos, wantComponent: COMPONENT_RESPONSE_FORMAT, wantKinds: "drrrrrrrrrrrrrrr"},
+		{name: "url without padding", block: positions.Url.Pos, wantComponent: COMPONENT_URL, wantKinds: "dddddddddddddddd"},
+		{name: "encoding options", block: positions.EncodingOptions.Pos, wantComponent: COMPONENT_ENCODING_OPTIONS, wantKinds: "drrrrrrrdrrrrrrr"},
+		{name: "headers meta", block: positions.RequestHeaders.Pos, wantComponent: COMPONENT_REQUEST_HEADERS, wantKinds: "dddddddddddddddd"},
+		{name: "header entry", block: positions.RequestHeaders.Pos + 1, wantComponent: COMPONENT_REQUEST_HEADERS, wantKinds: "ddddddddddddpppp"},
+		{name: "optional fields meta", block: positions.OptionalFields.Pos, wantComponent: COMPONENT_OPTIONAL_FIELDS, wantKinds: "drrrrrrrdddddddd"},
+		{name: "html result type", block: positions.OptionalFields.Pos + 1, wantComponent: COMPONENT_OPTIONAL_FIELDS, wantKinds: "drrrrrrrrrrrrrrr"},
+		{name: "content type length", block: positions.OptionalFields.Pos + 2, wantComponent: COMPONENT_OPTIONAL_FIELDS, wantKinds: "ddddddddrrrrrrrr"},
+		{name: "content type", block: positions.OptionalFields.Pos + 3, wantComponent: COMPONENT_OPTIONAL_FIELDS, wantKinds: "ddddddddddpppppp"},
+		{name: "body length", block: positions.OptionalFields.Pos + 4, wantComponent: COMPONENT_OPTIONAL_FIELDS, wantKinds: "ddddddddrrrrrrrr"},
+		{name: "trailing block", block: len(blocks) - 1, wantComponent: "", wantKinds: "pppppppppppppppp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := &blocks[tt.block]
+			if block.Index != tt.block {
+				t.Errorf("AnnotateBlocks() index = %d, want %d", block.Index, tt.block)
+			}
+			if block.Component != tt.wantComponent {
+				t.Errorf("AnnotateBlocks() component = %q, want %q", block.Component, tt.wantComponent)
+			}
+			if got := kindsString(block); got != tt.wantKinds {
+				t.Errorf("AnnotateBlocks() kinds = %s, want %s", got, tt.wantKinds)
+			}
+		})
+	}
+
+	if blocks[positions.Data.Pos].U128 != "125" {
+		t.Errorf("AnnotateBlocks() u128 = %s, want 125", blocks[positions.Data.Pos].U128)
+	}
+	if ascii := blocks[positions.Method.Pos].ASCII(); ascii != "POST____________" {
+		t.Errorf("AnnotatedBlock.ASCII() = %q, want %q", ascii, "POST____________")
+	}
+
+	t.Run("invalid data", func(t *testing.T) {
+		if _, err := AnnotateBlocks(encoded[:TARGET_ALIGNMENT]); err == nil {
+			t.Error("AnnotateBlocks() expected an error for invalid data")
+		}
+	})
+}
+
+func TestDump(t *testing.T) {
+	report := &testReport{
+		data:            "string <value>",
+		encodingOptions: EncodingOptions{Value: ENCODING_OPTION_STRING},
+		method:          "GET",
+		responseFormat:  RESPONSE_FORMAT_HTML,
+		url:             "example.com",
+		selector:        "/html",
+		headers:         map[string]string{},
+	}
+	encoded, positions := encodeTestReport(t, report)
+
+	// put a non-zero byte into the padding of the method
+	encoded[positions.Method.Pos*TARGET_ALIGNMENT+5] = 0xaa
+
+	text, err := DumpText(encoded)
+	if err != nil {
+		t.Fatalf("DumpText() error = %v", err)
+	}
+	for _, want := range []string{"BLOCK", "ASCII", "c0.f2", "data", "|string <value>__|", "47 45 54 00 00 aa! 00", "optionalFields"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("DumpText() doesn't contain %q:\n%s", want, text)
+		}
+	}
+
+	htmlDump, err := DumpHTML(encoded)
+	if err != nil {
+		t.Fatalf("DumpHTML() error = %v", err)
+	}
+	for _, want := range []string{`<table class="aleo-oracle-dump">`, `<span class="data">&lt;</span>`, `<span class="padding nonzero">aa</span>`, `<span class="reserved">00</span>`, "<td>c0.f3</td>"} {
+		if !strings.Contains(htmlDump, want) {
+			t.Errorf("DumpHTML() doesn't contain %q", want)
+		}
+	}
+
+	if _, err := DumpHTML(nil); err == nil {
+		t.Error("DumpHTML() expected an error for empty data")
+	}
+}
+
+func TestDumpMalformedComponents(t *testing.T) {
+	// a meta header with canonical fixed lengths and all other lengths set to 0, followed by 1 block of zeroes for every component
+	encoded := make([]byte, (META_HEADER_BLOCKS+10)*TARGET_ALIGNMENT)
+	if err := CreateMetaHeader(encoded[:META_HEADER_BLOCKS*TARGET_ALIGNMENT], 0, 0, 0, 0, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LocateComponents(encoded); err != nil {
+		t.Fatalf("LocateComponents() error = %v", err)
+	}
+
+	if _, err := AnnotateBlocks(encoded); err != nil {
+		t.Errorf("AnnotateBlocks() error = %v", err)
+	}
+	if _, err := DumpText(encoded); err != nil {
+		t.Errorf("DumpText() error = %v", err)
+	}
+	if _, err := DumpHTML(encoded); err != nil {
+		t.Errorf("DumpHTML() error = %v", err)
+	}
+	if _, err := DiffReports(encoded, encoded); err != nil {
+		t.Errorf("DiffReports() error = %v", err)
+	}
+
+	// a meta header with zero fixed lengths is rejected instead of being dumped
+	if _, _, err := LocateComponents(make([]byte, len(encoded))); !errors.Is(err, ErrDecodingMetaHeaderFixedLength) {
+		t.Errorf("LocateComponents() error = %v, want %v", err, ErrDecodingMetaHeaderFixedLength)
+	}
+}
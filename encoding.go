@@ -1,14 +1,12 @@
 package aleoOracleEncoding
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"log"
 	"math"
 	"math/big"
-	"sort"
 	"strconv"
 	"strings"
 
@@ -17,6 +15,7 @@ import (
 
 var (
 	ErrEncodingMetaHeaderInvalidSize              = errors.New("encoding general meta header requires a 2-block buffer")
+	ErrEncodingMetaHeaderUnknownFlags             = errors.New("meta header flags contain unknown bits")
 	ErrIntValueParseFailure                       = errors.New("extracted value expected to be int but failed to parse as int")
 	ErrFloatValueEncodingPrecisionTooBig          = errors.New("encoding precision is too big")
 	ErrFloatNegativeUnsupported                   = errors.New("negative numbers are not supported for floats")
@@ -30,7 +29,9 @@ var (
 	ErrResponseFormatUnknown                      = errors.New("unknown response type")
 	ErrHtmlResultTypeUnknown                      = errors.New("HTML result type is unknown")
 
-	ErrDecodingInvalidMetaHeader = errors.New("invalid general meta header")
+	ErrDecodingInvalidMetaHeader      = errors.New("invalid general meta header")
+	ErrDecodingMetaHeaderUnknownFlags = errors.New("meta header has unknown flags set")
+	ErrDecodingMetaHeaderFixedLength  = errors.New("meta header has an unexpected length of a fixed-length component")
 
 	ErrDecodingBufferTooShort        = errors.New("cannot decode buffer of unexpected size")
 	ErrDecodingUnexpectedPadding     = errors.New("buffer contains unexpected padding")
@@ -42,18 +43,24 @@ var (
 	ErrDecodingHeadersInvalidHeaderEncoding  = errors.New("encoded headers entry is not a valid encoded header - no separator found")
 	ErrDecodingHeadersEmptyHeader            = errors.New("encoded headers entry is an empty header")
 	ErrDecodingHeadersCountProcessedMismatch = errors.New("number of processed headers doesn't match number of headers in meta header")
+	ErrDecodingHeadersDuplicateHeader        = errors.New("encoded headers contain a duplicate header")
 
 	ErrDecodingOptionalsCountLengthMismatch      = errors.New("buffer length doesn't match encoded length in meta header")
 	ErrDecodingOptionalsInvalidContentTypeLength = errors.New("encoded request content type length is bigger than buffer")
 	ErrDecodingOptionalsInvalidBodyLength        = errors.New("encoded request body length is bigger than buffer")
 	ErrDecodingOptionalsInvalidEncoding          = errors.New("could not parse the whole buffer")
+	ErrDecodingOptionalsUnsupportedFlags         = errors.New("optional fields header has flags, which are not supported by DecodeOptionalFields")
 )
 
 const (
 	TARGET_ALIGNMENT = 16
 
-	RESPONSE_FORMAT_JSON_VALUE = 0 // value used for encoding response format for Aleo
-	RESPONSE_FORMAT_HTML_VALUE = 1 // value used for encoding response format for Aleo
+	RESPONSE_FORMAT_JSON_VALUE    = 0 // value used for encoding response format for Aleo
+	RESPONSE_FORMAT_HTML_VALUE    = 1 // value used for encoding response format for Aleo
+	RESPONSE_FORMAT_XML_VALUE     = 2 // value used for encoding response format for Aleo
+	RESPONSE_FORMAT_CSV_VALUE     = 3 // value used for encoding response format for Aleo
+	RESPONSE_FORMAT_TEXT_VALUE    = 4 // value used for encoding response format for Aleo
+	RESPONSE_FORMAT_GRAPHQL_VALUE = 5 // value used for encoding response format for Aleo
 
 	ENCODING_OPTION_STRING_VALUE = 0 // value used for encoding encoding value format for Aleo
 	ENCODING_OPTION_INT_VALUE    = 1 // value used for encoding encoding value format for Aleo
@@ -63,6 +70,19 @@ const (
 	OPTIONAL_FIELDS_HEADER_HAS_CONTENT_TYPE     = 2 // bit flag used for encoding presence of request content type for Aleo
 	OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY     = 4 // bit flag used for encoding presence of request body for Aleo
 
+	// bit flags of the optional fields header, which are known to DecodeOptionalFields
+	legacyOptionalFieldsHeaderFlags = OPTIONAL_FIELDS_HEADER_HAS_HTML_RESULT_TYPE | OPTIONAL_FIELDS_HEADER_HAS_CONTENT_TYPE | OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY
+
+	META_HEADER_FLAGS_POSITION      = 20 // position of the flags byte in the meta header
+	META_HEADER_FLAG_COMPACT_METHOD = 1  // bit flag used for encoding the request method as a method code for Aleo
+	META_HEADER_KNOWN_FLAGS         = META_HEADER_FLAG_COMPACT_METHOD
+
+	// lengths of the fixed-length components in the meta header
+	META_HEADER_TIMESTAMP_LEN        = 8  // timestamp is encoded as uint64
+	META_HEADER_STATUS_CODE_LEN      = 8  // status code is encoded as uint64
+	META_HEADER_RESPONSE_FORMAT_LEN  = 1  // response format is encoded as 1 byte
+	META_HEADER_ENCODING_OPTIONS_LEN = 16 // encoding options are encoded as 2 uint64 numbers
+
 	HTML_RESULT_TYPE_ELEMENT_VALUE = 1 // value used for encoding HTML result type for Aleo
 	HTML_RESULT_TYPE_VALUE_VALUE   = 2 // value used for encoding HTML result type for Aleo
 
@@ -75,8 +95,12 @@ const (
 	// Extracted value is an unsigned floating point number up to 64 bits in size
 	ENCODING_OPTION_FLOAT = "float"
 
-	RESPONSE_FORMAT_HTML = "html"
-	RESPONSE_FORMAT_JSON = "json"
+	RESPONSE_FORMAT_HTML    = "html"
+	RESPONSE_FORMAT_JSON    = "json"
+	RESPONSE_FORMAT_XML     = "xml"     // XML documents and feeds, e.g. RSS or Atom
+	RESPONSE_FORMAT_CSV     = "csv"     // comma-separated values
+	RESPONSE_FORMAT_TEXT    = "text"    // plain text
+	RESPONSE_FORMAT_GRAPHQL = "graphql" // GraphQL response
 
 	HTML_RESULT_TYPE_ELEMENT = "element"
 	HTML_RESULT_TYPE_VALUE   = "value"
@@ -169,16 +193,16 @@ func CreateMetaHeader(header []byte, attestationDataLen, methodLen, urlLen, sele
 	binary.LittleEndian.PutUint16(header[0:2], attestationDataLen)
 
 	// write timestamp length
-	binary.LittleEndian.PutUint16(header[2:4], 8) // timestamp is encoded as uint64 so it's always 8 bytes
+	binary.LittleEndian.PutUint16(header[2:4], META_HEADER_TIMESTAMP_LEN)
 
 	// write status code length
-	binary.LittleEndian.PutUint16(header[4:6], 8) // status code is encoded as uint64 so it's always 8 bytes
+	binary.LittleEndian.PutUint16(header[4:6], META_HEADER_STATUS_CODE_LEN)
 
 	// write method length
 	binary.LittleEndian.PutUint16(header[6:8], methodLen)
 
 	// write response format length
-	binary.LittleEndian.PutUint16(header[8:10], 1) // response format is always encoded as 1 byte
+	binary.LittleEndian.PutUint16(header[8:10], META_HEADER_RESPONSE_FORMAT_LEN)
 
 	// write URL length
 	binary.LittleEndian.PutUint16(header[10:12], urlLen)
@@ -187,7 +211,7 @@ func CreateMetaHeader(header []byte, attestationDataLen, methodLen, urlLen, sele
 	binary.LittleEndian.PutUint16(header[12:14], selectorLen)
 
 	// write encoding options length
-	binary.LittleEndian.PutUint16(header[14:16], 16) // encoding options are encoded as 2 uint64 numbers so it's always 16 bytes
+	binary.LittleEndian.PutUint16(header[14:16], META_HEADER_ENCODING_OPTIONS_LEN)
 
 	// write headers length
 	binary.LittleEndian.PutUint16(header[16:18], headersLen)
@@ -198,6 +222,20 @@ func CreateMetaHeader(header []byte, attestationDataLen, methodLen, urlLen, sele
 	return nil
 }
 
+// sets the flags byte of a meta header created with CreateMetaHeader. Only META_HEADER_FLAG_* bits are allowed
+func SetMetaHeaderFlags(header []byte, flags byte) error {
+	if len(header) != TARGET_ALIGNMENT*2 {
+		return ErrEncodingMetaHeaderInvalidSize
+	}
+	if flags&^META_HEADER_KNOWN_FLAGS != 0 {
+		return fmt.Errorf("%w: %d", ErrEncodingMetaHeaderUnknownFlags, flags)
+	}
+
+	header[META_HEADER_FLAGS_POSITION] = flags
+
+	return nil
+}
+
 type MetaHeader struct {
 	AttestationDataLen int
 	TimestampLen       int
@@ -209,6 +247,13 @@ type MetaHeader struct {
 	EncodingOptionsLen int
 	HeadersLen         int
 	OptionalFieldsLen  int
+	// bitmask of META_HEADER_FLAG_* values
+	Flags byte
+}
+
+// returns true if the request method is encoded with EncodeMethod as a method code
+func (h *MetaHeader) HasCompactMethod() bool {
+	return h.Flags&META_HEADER_FLAG_COMPACT_METHOD != 0
 }
 
 func DecodeMetaHeader(header []byte) (parsedHeader *MetaHeader, err error) {
@@ -217,7 +262,13 @@ func DecodeMetaHeader(header []byte) (parsedHeader *MetaHeader, err error) {
 		return
 	}
 
-	return &MetaHeader{
+	flags := header[META_HEADER_FLAGS_POSITION]
+	if flags&^META_HEADER_KNOWN_FLAGS != 0 {
+		err = fmt.Errorf("%w: %d", ErrDecodingMetaHeaderUnknownFlags, flags)
+		return
+	}
+
+	parsedHeader = &MetaHeader{
 		AttestationDataLen: int(binary.LittleEndian.Uint16(header[0:2])),
 		TimestampLen:       int(binary.LittleEndian.Uint16(header[2:4])),
 		StatusCodeLen:      int(binary.LittleEndian.Uint16(header[4:6])),
@@ -228,7 +279,15 @@ func DecodeMetaHeader(header []byte) (parsedHeader *MetaHeader, err error) {
 		EncodingOptionsLen: int(binary.LittleEndian.Uint16(header[14:16])),
 		HeadersLen:         int(binary.LittleEndian.Uint16(header[16:18])),
 		OptionalFieldsLen:  int(binary.LittleEndian.Uint16(header[18:20])),
-	}, nil
+		Flags:              flags,
+	}
+
+	// the fixed lengths are always written by CreateMetaHeader, any other value means the header is corrupted
+	if name, length, ok := parsedHeader.nonCanonicalFixedLength(); ok {
+		return nil, fmt.Errorf("%w: %s length is %d bytes", ErrDecodingMetaHeaderFixedLength, name, length)
+	}
+
+	return parsedHeader, nil
 }
 
 // parses the data string as a decimal 64-bit number and converts it to 8 bytes in little-endian order
@@ -343,14 +402,27 @@ func prepareDataAsFloat(str string, precision uint) ([]byte, error) {
 	return NumberToBytes(anotherAdjustedNumber), nil
 }
 
-// writes data to the buffer, padding it to TARGET_ALIGNMENT bytes if needed.
-// Returns the position info for the written aligned blocks
+// writes data to the buffer, padding it to TARGET_ALIGNMENT bytes if needed. Empty data is written as 1 block of zeroes.
+// Returns the position info recorded by the recorder for the written aligned blocks. If the recorder implements positionRecorder.PaddingRecorder,
+// the position info also includes the byte offset, the length of data and the length of padding.
 func WriteWithPadding(rec positionRecorder.PositionRecorder, data []byte) (*positionRecorder.PositionInfo, error) {
 	padding := getPadding(data, TARGET_ALIGNMENT)
+	if len(data) == 0 {
+		padding = make([]byte, TARGET_ALIGNMENT)
+	}
 	buffer := make([]byte, 0, len(data)+len(padding))
 	buffer = append(buffer, data...)
 	buffer = append(buffer, padding...)
 
+	if paddingRec, ok := rec.(positionRecorder.PaddingRecorder); ok {
+		if _, err := paddingRec.WritePadded(buffer, len(padding)); err != nil {
+			log.Printf("writeWithPadding: err=%s\n", err)
+			return nil, ErrWritePaddingFailure
+		}
+
+		return rec.GetLastWrite(), nil
+	}
+
 	n, err := rec.Write(buffer)
 	if n != len(data)+len(padding) || err != nil {
 		log.Printf("writeWithPadding: n=%d err=%s\n", n, err)
@@ -405,6 +477,10 @@ func DecodeAttestationData(buf []byte, stringLen int, options *EncodingOptions)
 		return "", ErrDecodingAttestationImpossible
 	}
 
+	if stringLen < 0 {
+		return "", ErrDecodingBufferTooShort
+	}
+
 	switch options.Value {
 	case ENCODING_OPTION_STRING:
 		if stringLen > len(buf) {
@@ -417,6 +493,10 @@ func DecodeAttestationData(buf []byte, stringLen int, options *EncodingOptions)
 		return strconv.FormatUint(number, 10), nil
 
 	case ENCODING_OPTION_FLOAT:
+		if options.Precision > ENCODING_OPTION_FLOAT_MAX_PRECISION {
+			return "", ErrFloatValueEncodingPrecisionTooBig
+		}
+
 		number := BytesToNumber(buf[:TARGET_ALIGNMENT/2])
 		float := new(big.Float).SetUint64(number).SetPrec(64).SetMode(big.ToNearestAway)
 		magnitude := new(big.Float).SetUint64(pow(10, uint64(options.Precision)))
@@ -441,7 +521,8 @@ func DecodeAttestationData(buf []byte, stringLen int, options *EncodingOptions)
 	}
 }
 
-// Encodes response format type as 1 block. The first little-endian byte encodes the format type - 1 for HTML, 0 for JSON
+// Encodes response format type as 1 block. The first little-endian byte encodes the format type - 0 for JSON, 1 for HTML, 2 for XML, 3 for CSV,
+// 4 for plain text, 5 for GraphQL
 func EncodeResponseFormat(format string) ([]byte, error) {
 	buf := make([]byte, TARGET_ALIGNMENT)
 	switch format {
@@ -449,6 +530,14 @@ func EncodeResponseFormat(format string) ([]byte, error) {
 		buf[0] = RESPONSE_FORMAT_HTML_VALUE
 	case RESPONSE_FORMAT_JSON:
 		buf[0] = RESPONSE_FORMAT_JSON_VALUE
+	case RESPONSE_FORMAT_XML:
+		buf[0] = RESPONSE_FORMAT_XML_VALUE
+	case RESPONSE_FORMAT_CSV:
+		buf[0] = RESPONSE_FORMAT_CSV_VALUE
+	case RESPONSE_FORMAT_TEXT:
+		buf[0] = RESPONSE_FORMAT_TEXT_VALUE
+	case RESPONSE_FORMAT_GRAPHQL:
+		buf[0] = RESPONSE_FORMAT_GRAPHQL_VALUE
 	default:
 		return nil, ErrResponseFormatUnknown
 	}
@@ -467,6 +556,14 @@ func DecodeResponseFormat(buf []byte) (string, error) {
 		return RESPONSE_FORMAT_HTML, nil
 	case RESPONSE_FORMAT_JSON_VALUE:
 		return RESPONSE_FORMAT_JSON, nil
+	case RESPONSE_FORMAT_XML_VALUE:
+		return RESPONSE_FORMAT_XML, nil
+	case RESPONSE_FORMAT_CSV_VALUE:
+		return RESPONSE_FORMAT_CSV, nil
+	case RESPONSE_FORMAT_TEXT_VALUE:
+		return RESPONSE_FORMAT_TEXT, nil
+	case RESPONSE_FORMAT_GRAPHQL_VALUE:
+		return RESPONSE_FORMAT_GRAPHQL, nil
 	default:
 		return "", ErrResponseFormatUnknown
 	}
@@ -520,6 +617,9 @@ func DecodeEncodingOptions(buf []byte) (*EncodingOptions, error) {
 	case ENCODING_OPTION_INT_VALUE:
 		return &EncodingOptions{Value: ENCODING_OPTION_INT, Precision: 0}, nil
 	case ENCODING_OPTION_FLOAT_VALUE:
+		if precisionByte > ENCODING_OPTION_FLOAT_MAX_PRECISION {
+			return nil, ErrFloatValueEncodingPrecisionTooBig
+		}
 		return &EncodingOptions{Value: ENCODING_OPTION_FLOAT, Precision: uint(precisionByte)}, nil
 	default:
 		return nil, ErrValueEncodingUnknown
@@ -531,111 +631,28 @@ func DecodeEncodingOptions(buf []byte) (*EncodingOptions, error) {
 // 2+ blocks - 2 bytes of "header:value" length + "header:value" + pad to TARGET_ALIGNMENT, repeat for all headers
 // the headers are sorted alphabetically
 func EncodeHeaders(headers map[string]string) []byte {
-	buf := make([]byte, TARGET_ALIGNMENT, len(headers)*TARGET_ALIGNMENT+TARGET_ALIGNMENT)
-
-	// collect keys first and sort them
-	keys := make([]string, 0, len(headers))
-	for key := range headers {
-		keys = append(keys, key)
+	list := make([]Header, 0, len(headers))
+	for name, value := range headers {
+		list = append(list, Header{Name: name, Value: value})
 	}
 
-	sort.Strings(keys)
-
-	for _, key := range keys {
-		val := headers[key]
-		entry := []byte(fmt.Sprintf("%s:%s", key, val))
-		lenBuf := make([]byte, 2)
-		binary.LittleEndian.PutUint16(lenBuf, uint16(len(entry)))
-		entry = append(lenBuf, entry...)
-
-		padding := getPadding(entry, TARGET_ALIGNMENT)
-
-		buf = append(buf, entry...)
-		buf = append(buf, padding...)
-	}
-
-	numHeaders := uint64(len(headers))
-	copy(buf[:TARGET_ALIGNMENT/2], NumberToBytes(numHeaders))
-
-	// the first block is the one where we're writing length so we're not counting it
-	numBlocks := uint64(len(buf)/TARGET_ALIGNMENT - 1)
-	copy(buf[TARGET_ALIGNMENT/2:TARGET_ALIGNMENT], NumberToBytes(numBlocks))
-
-	return buf
+	return EncodeHeaderList(list)
 }
 
+// Decodes headers created with EncodeHeaders. Returns ErrDecodingHeadersDuplicateHeader if a header name is encoded more than once,
+// use DecodeHeaderList to decode such headers.
 func DecodeHeaders(buf []byte) (map[string]string, error) {
-	if len(buf) < TARGET_ALIGNMENT {
-		return nil, ErrDecodingBufferTooShort
-	}
-
-	headers := make(map[string]string)
-
-	// if there's only one block, then it's a block header with no content
-	if len(buf) == TARGET_ALIGNMENT {
-		return headers, nil
-	}
-
-	parsedBlockHeader := BlockToNumbers(buf[:TARGET_ALIGNMENT])
-	if len(parsedBlockHeader) != 2 {
-		return nil, ErrDecodingHeadersInvalidBlockHeader
-	}
-
-	headerCount := parsedBlockHeader[0]
-	blockCount := parsedBlockHeader[1]
-
-	// verify that the encoded block length + block header matches the buffer length
-	if len(buf) != int(blockCount+1)*TARGET_ALIGNMENT {
-		return nil, ErrDecodingHeadersCountLengthMismatch
+	list, err := DecodeHeaderList(buf)
+	if err != nil {
+		return nil, err
 	}
 
-	headersProcessed := 0
-	byteOffset := TARGET_ALIGNMENT
-	for byteOffset < len(buf) {
-		// read 2 bytes of header length and convert it to a number
-		entryLenBuf := buf[byteOffset : byteOffset+2]
-		byteOffset += 2
-
-		// decode the length of an entry
-		entryLen := int(binary.LittleEndian.Uint16(entryLenBuf))
-		if byteOffset+entryLen > len(buf) {
-			return nil, ErrDecodingHeadersInvalidHeaderLength
-		}
-
-		// get the entry
-		entry := buf[byteOffset : byteOffset+entryLen]
-		byteOffset += entryLen
-
-		// an entry is formatted as "header:value", split around the first colon
-		header, value, found := strings.Cut(string(entry), ":")
-		if !found {
-			return nil, ErrDecodingHeadersInvalidHeaderEncoding
-		}
-		if header == "" {
-			return nil, ErrDecodingHeadersEmptyHeader
-		}
-
-		headers[header] = value
-		headersProcessed += 1
-
-		// check if this header entry was padded, skip the padding if it was
-		currentAlignment := byteOffset % TARGET_ALIGNMENT
-		if currentAlignment != 0 {
-			paddingBytes := TARGET_ALIGNMENT - currentAlignment
-			padding := buf[byteOffset : byteOffset+paddingBytes]
-			expectedPadding := make([]byte, paddingBytes)
-			// may be an overkill to verify that the padding used is made of zeroes?
-			if !bytes.Equal(padding, expectedPadding) {
-				return nil, ErrDecodingUnexpectedPadding
-			}
-
-			byteOffset += paddingBytes
+	headers := make(map[string]string, len(list))
+	for _, header := range list {
+		if _, ok := headers[header.Name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDecodingHeadersDuplicateHeader, header.Name)
 		}
-	}
-
-	// verify that we've got the same number of headers as the encoding say
-	if headersProcessed != int(headerCount) {
-		return nil, ErrDecodingHeadersCountProcessedMismatch
+		headers[header.Name] = header.Value
 	}
 
 	return headers, nil
@@ -649,32 +666,64 @@ func DecodeHeaders(buf []byte) (map[string]string, error) {
 //
 // The header is followed by the following content:
 //
-// 1. 1 block encoding HTML result type. The first little endian byte encodes the value - 1 for "element", 2 for "value".
-// If there's no HTML result type, then the whole block is 0.
+// 1. 1 block encoding HTML result type. The first little endian byte encodes the value - 1 for "element", 2 for "value", 3 for "attribute".
+// If there's no HTML result type, then the whole block is 0. The "attribute" type, see HtmlAttributeResultType, is followed by 1 block, where
+// the first 8 little endian bytes encode the length of the attribute name, and the attribute name as character codes.
 //
 // 2. At least 1 block encoding request content type. The first 8 little endian bytes encode the number of the following blocks encoding the actual content type as character
 // codes. If there is no content type, there's 1 block of 0, followed by 0 blocks of content.
 //
 // 3. At least 1 block encoding request body. The first 8 little endian bytes encode the number of the following blocks encoding the actual request body as character codes.
 // If there is no request body, there's 1 block of 0, followed by 0 blocks of content.
+//
+// Use EncodeOptionalFieldsWithOptions to canonicalize a JSON request body.
 func EncodeOptionalFields(htmlResultType, requestContentType, requestBody *string) ([]byte, error) {
+	return EncodeOptionalFieldsWithOptions(htmlResultType, requestContentType, requestBody, nil)
+}
+
+// OptionalFieldsEncodingOptions configures EncodeOptionalFieldsWithOptions
+type OptionalFieldsEncodingOptions struct {
+	// Canonicalizes the request body with CanonicalizeJSON before encoding. The request content type must be JSON.
+	CanonicalizeRequestBody bool
+}
+
+// Encodes optional fields like EncodeOptionalFields. If options.CanonicalizeRequestBody is set, the request body is canonicalized with CanonicalizeJSON
+// and OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY bit is set in the header, so verifiers know that canonicalization was applied. Canonicalization requires
+// a request body and a JSON request content type, otherwise ErrOptionalFieldsCanonicalizationNotJSON is returned.
+// Options can be nil, then the result is the same as the result of EncodeOptionalFields.
+func EncodeOptionalFieldsWithOptions(htmlResultType, requestContentType, requestBody *string, options *OptionalFieldsEncodingOptions) ([]byte, error) {
+	if options == nil {
+		options = &OptionalFieldsEncodingOptions{}
+	}
+
 	header := make([]byte, TARGET_ALIGNMENT)
+	if options.CanonicalizeRequestBody {
+		if requestContentType == nil || !isJSONContentType(*requestContentType) || requestBody == nil {
+			return nil, ErrOptionalFieldsCanonicalizationNotJSON
+		}
+
+		canonical, err := CanonicalizeJSON([]byte(*requestBody))
+		if err != nil {
+			return nil, err
+		}
+		body := string(canonical)
+		requestBody = &body
+		header[0] |= OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY
+	}
+
 	var htmlResultTypeBuf, contentTypeBuf, requestBodyBuf []byte
 
 	// if there's HTML result type, set the byte in the header,
-	// encode the type in 1 block.
+	// encode the type in 1 block, followed by the attribute name for the attribute type.
 	// if there's no HTML type, write one block of zeros.
 	htmlResultTypeBuf = make([]byte, TARGET_ALIGNMENT)
 	if htmlResultType != nil {
 		header[0] |= OPTIONAL_FIELDS_HEADER_HAS_HTML_RESULT_TYPE
 
-		switch *htmlResultType {
-		case HTML_RESULT_TYPE_ELEMENT:
-			htmlResultTypeBuf[0] = HTML_RESULT_TYPE_ELEMENT_VALUE
-		case HTML_RESULT_TYPE_VALUE:
-			htmlResultTypeBuf[0] = HTML_RESULT_TYPE_VALUE_VALUE
-		default:
-			return nil, ErrHtmlResultTypeUnknown
+		var err error
+		htmlResultTypeBuf, err = encodeHtmlResultType(*htmlResultType)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -727,7 +776,19 @@ func EncodeOptionalFields(htmlResultType, requestContentType, requestBody *strin
 	return result, nil
 }
 
+// Decodes optional fields created with EncodeOptionalFields. Returns no fields if the buffer cannot be decoded.
+// Optional fields with flags other than the presence of the 3 fields, e.g. a body commitment, are rejected with ErrDecodingOptionalsUnsupportedFlags,
+// use DecodeOptionalFieldMap to decode them.
+//
+// The signature of 3 string pointers is kept for the existing callers. DecodeOptionalFieldMap is the typed counterpart, which returns all fields as a map.
 func DecodeOptionalFields(buf []byte) (htmlResultType, requestContentType, requestBody *string, err error) {
+	// don't return partially decoded fields
+	defer func() {
+		if err != nil {
+			htmlResultType, requestContentType, requestBody = nil, nil, nil
+		}
+	}()
+
 	if len(buf) < 4*TARGET_ALIGNMENT {
 		err = ErrDecodingBufferTooShort
 		return
@@ -737,11 +798,17 @@ func DecodeOptionalFields(buf []byte) (htmlResultType, requestContentType, reque
 	blockCount := BytesToNumber(header[TARGET_ALIGNMENT/2 : TARGET_ALIGNMENT])
 
 	// check that the header encodes the correct number of of the following blocks
-	if (blockCount+1)*TARGET_ALIGNMENT != uint64(len(buf)) {
+	if len(buf)%TARGET_ALIGNMENT != 0 || blockCount != uint64(len(buf)/TARGET_ALIGNMENT-1) {
 		err = ErrDecodingOptionalsCountLengthMismatch
 		return
 	}
 
+	// extensions, body commitments and canonicalized bodies can only be decoded with DecodeOptionalFieldMap
+	if header[0]&^legacyOptionalFieldsHeaderFlags != 0 {
+		err = fmt.Errorf("%w: %d", ErrDecodingOptionalsUnsupportedFlags, header[0])
+		return
+	}
+
 	// no optional fields are present
 	if header[0] == 0 {
 		return
@@ -754,60 +821,52 @@ func DecodeOptionalFields(buf []byte) (htmlResultType, requestContentType, reque
 	blockOffset := 1 * TARGET_ALIGNMENT
 
 	if hasHtmlResultType {
-		// html result type is encoded in the first byte of the first block after the meta header
-		switch buf[blockOffset] {
-		case HTML_RESULT_TYPE_ELEMENT_VALUE:
-			htmlResultType = new(string)
-			*htmlResultType = HTML_RESULT_TYPE_ELEMENT
-		case HTML_RESULT_TYPE_VALUE_VALUE:
-			htmlResultType = new(string)
-			*htmlResultType = HTML_RESULT_TYPE_VALUE
-		default:
-			err = ErrHtmlResultTypeUnknown
+		// html result type is encoded in the first byte of the first block after the meta header, the attribute type is followed by the attribute name
+		var resultType string
+		resultType, blockOffset, err = decodeHtmlResultType(buf, blockOffset)
+		if err != nil {
 			return
 		}
+
+		htmlResultType = new(string)
+		*htmlResultType = resultType
+	} else {
+		blockOffset += 1 * TARGET_ALIGNMENT
 	}
-	blockOffset += 1 * TARGET_ALIGNMENT
 
 	if hasRequestContentType {
-		contentTypeHeader := buf[blockOffset : blockOffset+TARGET_ALIGNMENT]
-		// parse the length of the content
-		contentTypeLen := BytesToNumber(contentTypeHeader[:TARGET_ALIGNMENT/2])
-		// check if encoded length makes sense. We add one more block to the length here to account for the request body header block, which comes after content type
-		if blockOffset+int(contentTypeLen)+TARGET_ALIGNMENT > len(buf) {
+		// read the length block and the content type, then skip the padding
+		var contentType []byte
+		contentType, blockOffset, err = readLengthPrefixed(buf, blockOffset)
+		if err == ErrDecodingBufferTooShort {
 			err = ErrDecodingOptionalsInvalidContentTypeLength
+		}
+		if err != nil {
 			return
 		}
 
-		// skip the content type header and read the content type, then figure out if there was padding and skip it
-		contentType := buf[blockOffset+TARGET_ALIGNMENT : blockOffset+TARGET_ALIGNMENT+int(contentTypeLen)]
-		padding := getPadding(contentType, TARGET_ALIGNMENT)
-
 		requestContentType = new(string)
 		*requestContentType = string(contentType)
-		blockOffset += int(contentTypeLen) + len(padding)
+	} else {
+		blockOffset += 1 * TARGET_ALIGNMENT
 	}
-	blockOffset += 1 * TARGET_ALIGNMENT
 
 	if hasRequestBody {
-		requestBodyHeader := buf[blockOffset : blockOffset+TARGET_ALIGNMENT]
-		// parse the length of the content
-		requestBodyLen := BytesToNumber(requestBodyHeader[:TARGET_ALIGNMENT/2])
-		// check if encoded length makes sense. This is the last header, so no need to add anything like we did for content type.
-		if blockOffset+int(requestBodyLen) > len(buf) {
-			err = ErrDecodingOptionalsInvalidContentTypeLength
+		// read the length block and the request body, then skip the padding
+		var body []byte
+		body, blockOffset, err = readLengthPrefixed(buf, blockOffset)
+		if err == ErrDecodingBufferTooShort {
+			err = ErrDecodingOptionalsInvalidBodyLength
+		}
+		if err != nil {
 			return
 		}
 
-		// skip the content type header and read the content type, then figure out if there was padding and skip it
-		body := buf[blockOffset+TARGET_ALIGNMENT : blockOffset+TARGET_ALIGNMENT+int(requestBodyLen)]
-		padding := getPadding(body, TARGET_ALIGNMENT)
-
 		requestBody = new(string)
 		*requestBody = string(body)
-		blockOffset += int(requestBodyLen) + len(padding)
+	} else {
+		blockOffset += 1 * TARGET_ALIGNMENT
 	}
-	blockOffset += 1 * TARGET_ALIGNMENT
 
 	if blockOffset != len(buf) {
 		err = ErrDecodingOptionalsInvalidEncoding
@@ -355,43 +355,89 @@ func TestDecodeMetaHeader(t *testing.T) {
 			wantErr:          true,
 		},
 		{
-			name: "valid buffer",
+			name: "zero fixed lengths",
 			args: args{
 				header: make([]byte, TARGET_ALIGNMENT*2),
 			},
-			wantParsedHeader: &MetaHeader{
-				AttestationDataLen: 0,
-				TimestampLen:       0,
-				StatusCodeLen:      0,
-				MethodLen:          0,
-				ResponseFormatLen:  0,
-				UrlLen:             0,
-				SelectorLen:        0,
-				EncodingOptionsLen: 0,
-				HeadersLen:         0,
-				OptionalFieldsLen:  0,
-			},
-			wantErr: false,
+			wantParsedHeader: nil,
+			wantErr:          true,
 		},
 		{
-			name: "valid buffer 2",
+			name: "non-canonical fixed lengths",
 			args: args{
 				header: []byte{1, 0, 2, 0, 3, 0, 4, 0, 5, 0, 6, 0, 7, 0, 8, 0, 9, 0, 10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
 			},
+			wantParsedHeader: nil,
+			wantErr:          true,
+		},
+		{
+			name: "non-canonical encoding options length",
+			args: args{
+				header: []byte{1, 0, 8, 0, 8, 0, 4, 0, 1, 0, 6, 0, 7, 0, 0, 0, 9, 0, 10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+			wantParsedHeader: nil,
+			wantErr:          true,
+		},
+		{
+			name: "valid buffer",
+			args: args{
+				header: []byte{1, 0, 8, 0, 8, 0, 4, 0, 1, 0, 6, 0, 7, 0, 16, 0, 9, 0, 10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
 			wantParsedHeader: &MetaHeader{
 				AttestationDataLen: 1,
-				TimestampLen:       2,
-				StatusCodeLen:      3,
+				TimestampLen:       8,
+				StatusCodeLen:      8,
 				MethodLen:          4,
-				ResponseFormatLen:  5,
+				ResponseFormatLen:  1,
 				UrlLen:             6,
 				SelectorLen:        7,
-				EncodingOptionsLen: 8,
+				EncodingOptionsLen: 16,
 				HeadersLen:         9,
 				OptionalFieldsLen:  10,
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid buffer with zero variable lengths",
+			args: args{
+				header: []byte{0, 0, 8, 0, 8, 0, 0, 0, 1, 0, 0, 0, 0, 0, 16, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+			wantParsedHeader: &MetaHeader{
+				TimestampLen:       8,
+				StatusCodeLen:      8,
+				ResponseFormatLen:  1,
+				EncodingOptionsLen: 16,
+			},
+			wantErr: false,
+		},
+		{
+			name: "compact method flag",
+			args: args{
+				header: []byte{1, 0, 8, 0, 8, 0, 8, 0, 1, 0, 6, 0, 7, 0, 16, 0, 16, 0, 48, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+			wantParsedHeader: &MetaHeader{
+				AttestationDataLen: 1,
+				TimestampLen:       8,
+				StatusCodeLen:      8,
+				MethodLen:          8,
+				ResponseFormatLen:  1,
+				UrlLen:             6,
+				SelectorLen:        7,
+				EncodingOptionsLen: 16,
+				HeadersLen:         16,
+				OptionalFieldsLen:  48,
+				Flags:              META_HEADER_FLAG_COMPACT_METHOD,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown flags",
+			args: args{
+				header: []byte{1, 0, 8, 0, 8, 0, 8, 0, 1, 0, 6, 0, 7, 0, 16, 0, 16, 0, 48, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+			wantParsedHeader: nil,
+			wantErr:          true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -715,29 +761,52 @@ func Test_prepareDataAsFloat(t *testing.T) {
 	}
 }
 
+// a position recorder, which doesn't implement positionRecorder.PaddingRecorder
+type plainPositionRecorder struct {
+	recorder *positionRecorder.PositionRecordingProxy
+}
+
+func (r *plainPositionRecorder) Write(p []byte) (int, error) {
+	return r.recorder.Write(p)
+}
+
+func (r *plainPositionRecorder) GetLastWrite() *positionRecorder.PositionInfo {
+	return r.recorder.GetLastWrite()
+}
+
 func Test_WriteWithPadding(t *testing.T) {
 	tests := []struct {
-		name    string
-		data    []byte
-		wantErr bool
-		want    []byte
+		name     string
+		data     []byte
+		wantErr  bool
+		want     []byte
+		wantInfo positionRecorder.PositionInfo
 	}{
 		{
-			name:    "short",
-			data:    []byte{1, 1, 1, 1},
-			want:    []byte{1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
-			wantErr: false,
+			name:     "short",
+			data:     []byte{1, 1, 1, 1},
+			want:     []byte{1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			wantErr:  false,
+			wantInfo: positionRecorder.PositionInfo{Pos: 0, Len: 1, ByteOffset: 0, PayloadLen: 4, PaddingLen: 12},
 		},
 		{
-			name:    "long",
-			data:    []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
-			want:    []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0},
-			wantErr: false,
+			name:     "long",
+			data:     []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+			want:     []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0},
+			wantErr:  false,
+			wantInfo: positionRecorder.PositionInfo{Pos: 0, Len: 2, ByteOffset: 0, PayloadLen: 30, PaddingLen: 2},
 		}, {
-			name:    "no pad",
-			data:    []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
-			want:    []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
-			wantErr: false,
+			name:     "empty",
+			data:     nil,
+			want:     []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			wantErr:  false,
+			wantInfo: positionRecorder.PositionInfo{Pos: 0, Len: 1, ByteOffset: 0, PayloadLen: 0, PaddingLen: 16},
+		}, {
+			name:     "no pad",
+			data:     []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+			want:     []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+			wantErr:  false,
+			wantInfo: positionRecorder.PositionInfo{Pos: 0, Len: 1, ByteOffset: 0, PayloadLen: 16, PaddingLen: 0},
 		},
 	}
 	for _, tt := range tests {
@@ -745,7 +814,8 @@ func Test_WriteWithPadding(t *testing.T) {
 		recorder := positionRecorder.NewPositionRecorder(&b, 16)
 
 		t.Run(tt.name, func(t *testing.T) {
-			if _, err := WriteWithPadding(recorder, tt.data); (err != nil) != tt.wantErr {
+			info, err := WriteWithPadding(recorder, tt.data)
+			if (err != nil) != tt.wantErr {
 				t.Errorf("WriteWithPadding() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -753,8 +823,34 @@ func Test_WriteWithPadding(t *testing.T) {
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("prepareDataAsFloat() = %v, want %v", got, tt.want)
 			}
+
+			if info == nil || *info != tt.wantInfo {
+				t.Errorf("WriteWithPadding() info = %+v, want %+v", info, tt.wantInfo)
+			}
+			// the returned info is the one recorded by the recorder
+			if lastWrite := recorder.GetLastWrite(); info != lastWrite {
+				t.Errorf("WriteWithPadding() info = %p, recorded info = %p", info, lastWrite)
+			}
 		})
 	}
+
+	t.Run("recorder without byte-level information", func(t *testing.T) {
+		var b bytes.Buffer
+		recorder := &plainPositionRecorder{recorder: positionRecorder.NewPositionRecorder(&b, 16)}
+
+		if _, err := WriteWithPadding(recorder, []byte{1}); err != nil {
+			t.Fatal(err)
+		}
+		info, err := WriteWithPadding(recorder, []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := positionRecorder.PositionInfo{Pos: 1, Len: 2}
+		if *info != want || info != recorder.GetLastWrite() || info.HasByteInfo() {
+			t.Errorf("WriteWithPadding() info = %+v, want %+v recorded by the recorder", info, want)
+		}
+	})
 }
 
 func Test_EncodeAttestationData(t *testing.T) {
@@ -1502,8 +1598,32 @@ func Test_EncodeResponseFormat(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "invalid format",
+			name:    "xml",
 			format:  "xml",
+			want:    []byte{2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			wantErr: false,
+		},
+		{
+			name:    "csv",
+			format:  "csv",
+			want:    []byte{3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			wantErr: false,
+		},
+		{
+			name:    "text",
+			format:  "text",
+			want:    []byte{4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			wantErr: false,
+		},
+		{
+			name:    "graphql",
+			format:  "graphql",
+			want:    []byte{5, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			wantErr: false,
+		},
+		{
+			name:    "invalid format",
+			format:  "yaml",
 			want:    nil,
 			wantErr: true,
 		},
@@ -1580,10 +1700,42 @@ func TestDecodeResponseFormat(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "invalid format",
+			name: "xml",
 			args: args{
 				buf: []byte{2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
 			},
+			want:    RESPONSE_FORMAT_XML,
+			wantErr: false,
+		},
+		{
+			name: "csv",
+			args: args{
+				buf: []byte{3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+			want:    RESPONSE_FORMAT_CSV,
+			wantErr: false,
+		},
+		{
+			name: "text",
+			args: args{
+				buf: []byte{4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+			want:    RESPONSE_FORMAT_TEXT,
+			wantErr: false,
+		},
+		{
+			name: "graphql",
+			args: args{
+				buf: []byte{5, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+			want:    RESPONSE_FORMAT_GRAPHQL,
+			wantErr: false,
+		},
+		{
+			name: "invalid format",
+			args: args{
+				buf: []byte{6, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
 			want:    "",
 			wantErr: true,
 		},
@@ -2002,6 +2154,28 @@ func TestDecodeHeaders(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "block count overflows",
+			args: args{
+				buf: []byte{
+					1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0x10,
+					3, 0, 'a', ':', 'b', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "header count is bigger than block count",
+			args: args{
+				buf: []byte{
+					0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 1, 0, 0, 0, 0, 0, 0, 0,
+					3, 0, 'a', ':', 'b', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2402,6 +2576,50 @@ func TestDecodeOptionalFields(t *testing.T) {
 			wantErr:                false,
 			checkRoundTrip:         true,
 		},
+		{
+			name: "block count overflows",
+			args: args{
+				buf: append([]byte{0, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0x10}, make([]byte, 3*TARGET_ALIGNMENT)...),
+			},
+			wantErr: true,
+		},
+		{
+			name: "content type length overflows",
+			args: args{
+				buf: []byte{
+					2, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0,
+					0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+					0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0,
+					0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "request body is longer than buffer by less than a block",
+			args: args{
+				buf: []byte{
+					4, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0,
+					0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+					0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+					10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "content type padding overlaps request body block",
+			args: args{
+				buf: []byte{
+					6, 0, 0, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0,
+					0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+					20, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+					't', 'e', 'x', 't', '/', 'p', 'l', 'a', 'i', 'n', 0, 0, 0, 0, 0, 0,
+					0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
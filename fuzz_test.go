@@ -0,0 +1,212 @@
+package aleoOracleEncoding
+
+import (
+	"bytes"
+	"testing"
+)
+
+// The fuzz targets check that decoders and utilities, which parse encoded data, return errors instead of panicking on malformed input.
+// The seed corpus is in testdata/fuzz, run a target with "go test -fuzz=FuzzDecodeHeaders".
+
+func FuzzDecodeMetaHeader(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		header, err := DecodeMetaHeader(buf)
+		if err == nil && header == nil {
+			t.Error("DecodeMetaHeader() returned no header and no error")
+		}
+	})
+}
+
+func FuzzDecodeAttestationData(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte, stringLen int, valueType byte, precision uint) {
+		options := &EncodingOptions{Precision: precision}
+		switch valueType % 4 {
+		case 0:
+			options.Value = ENCODING_OPTION_STRING
+		case 1:
+			options.Value = ENCODING_OPTION_INT
+		case 2:
+			options.Value = ENCODING_OPTION_FLOAT
+		}
+
+		DecodeAttestationData(buf, stringLen, options)
+	})
+}
+
+func FuzzDecodeResponseFormat(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		DecodeResponseFormat(buf)
+	})
+}
+
+func FuzzDecodeTimestamp(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte, milliseconds bool) {
+		unit := TIMESTAMP_UNIT_SECONDS
+		if milliseconds {
+			unit = TIMESTAMP_UNIT_MILLISECONDS
+		}
+
+		timestamp, err := DecodeTimestamp(buf, unit)
+		if err != nil {
+			return
+		}
+
+		// decoded timestamps are encoded to the same block
+		if encoded, err := EncodeTimestamp(timestamp, unit); err != nil || !bytes.Equal(encoded[:TARGET_ALIGNMENT/2], buf[:TARGET_ALIGNMENT/2]) {
+			t.Errorf("EncodeTimestamp() = %v, %v for decoded timestamp %v", encoded, err, timestamp)
+		}
+	})
+}
+
+func FuzzDecodeStatusCode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		statusCode, err := DecodeStatusCode(buf)
+		if err != nil {
+			return
+		}
+
+		if _, err := EncodeStatusCode(statusCode); err != nil {
+			t.Errorf("EncodeStatusCode() error = %v for decoded status code %d", err, statusCode)
+		}
+	})
+}
+
+func FuzzDecodeMethod(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte, methodLen int, compact bool) {
+		method, err := DecodeMethod(buf, methodLen, compact)
+		if err != nil || !compact {
+			return
+		}
+
+		// decoded compact methods are encoded to the same code
+		if encoded, isCompact, err := EncodeMethod(method); err != nil || !isCompact || !bytes.Equal(encoded, buf[:METHOD_COMPACT_LENGTH]) {
+			t.Errorf("EncodeMethod() = %v, %v, %v for decoded method %s", encoded, isCompact, err, method)
+		}
+	})
+}
+
+func FuzzDecodeEncodingOptions(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		options, err := DecodeEncodingOptions(buf)
+		if err != nil {
+			return
+		}
+
+		// decoded options can always be encoded
+		if _, err := EncodeEncodingOptions(options); err != nil {
+			t.Errorf("EncodeEncodingOptions() error = %v for decoded options %+v", err, options)
+		}
+	})
+}
+
+func FuzzDecodeHeaders(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		DecodeHeaders(buf)
+	})
+}
+
+func FuzzDecodeHeaderList(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		headers, err := DecodeHeaderList(buf)
+		if err != nil {
+			return
+		}
+
+		// decoded headers are encoded to the same number of blocks
+		if encoded := EncodeHeaderList(headers); len(encoded) != len(buf) {
+			t.Errorf("EncodeHeaderList() encoded %d bytes, decoded %d bytes", len(encoded), len(buf))
+		}
+	})
+}
+
+func FuzzDecodeHTTPHeaders(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		DecodeHTTPHeaders(buf)
+	})
+}
+
+func FuzzDecodeOptionalFields(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		htmlResultType, requestContentType, requestBody, err := DecodeOptionalFields(buf)
+		if err != nil {
+			return
+		}
+
+		// decoded fields can always be encoded
+		if _, err := EncodeOptionalFields(htmlResultType, requestContentType, requestBody); err != nil {
+			t.Errorf("EncodeOptionalFields() error = %v for decoded fields", err)
+		}
+	})
+}
+
+func FuzzDecodeOptionalFieldMap(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		fields, err := DecodeOptionalFieldMap(buf)
+		if err != nil {
+			return
+		}
+
+		// decoded fields can always be encoded
+		if _, err := EncodeOptionalFieldMap(fields); err != nil {
+			t.Errorf("EncodeOptionalFieldMap() error = %v for decoded fields %v", err, fields)
+		}
+	})
+}
+
+func FuzzOptionalFieldsDecode(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		var fields OptionalFields
+		fields.Decode(buf)
+	})
+}
+
+func FuzzLocateComponents(f *testing.F) {
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		_, positions, err := LocateComponents(buf)
+		if err != nil {
+			return
+		}
+
+		// located components can be annotated and dumped
+		if len(buf) > LEO_MAX_BLOCKS*TARGET_ALIGNMENT {
+			return
+		}
+		if _, err := positions.LeoPaths(); err != nil {
+			t.Errorf("ProofPositionalInfo.LeoPaths() error = %v for located components %+v", err, positions)
+		}
+		if _, err := AnnotateBlocks(buf); err != nil {
+			t.Errorf("AnnotateBlocks() error = %v for located components %+v", err, positions)
+		}
+		DumpText(buf)
+		DumpHTML(buf)
+	})
+}
+
+func FuzzParseLeoStruct(f *testing.F) {
+	f.Fuzz(func(t *testing.T, literal string) {
+		buf, err := ParseLeoStruct(literal)
+		if err != nil {
+			return
+		}
+
+		// parsed structs are formatted to the same data
+		formatted, err := FormatLeoStruct(buf)
+		if err != nil {
+			t.Fatalf("FormatLeoStruct() error = %v for parsed data", err)
+		}
+		if parsed, err := ParseLeoStruct(formatted); err != nil || !bytes.Equal(parsed, buf) {
+			t.Errorf("ParseLeoStruct() = %v, %v for formatted data", parsed, err)
+		}
+	})
+}
+
+func FuzzDiffReports(f *testing.F) {
+	f.Fuzz(func(t *testing.T, a, b []byte) {
+		DiffReports(a, b)
+
+		// a report has no differences with itself
+		if differences, err := DiffReports(a, a); err == nil && len(differences) != 0 {
+			t.Errorf("DiffReports() = %v for identical reports", differences)
+		}
+	})
+}
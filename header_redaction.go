@@ -0,0 +1,105 @@
+package aleoOracleEncoding
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrEncodingHeadersRedactionSaltTooShort = errors.New("header redaction salt is too short")
+)
+
+const (
+	// The first byte of a redacted header value. A valid header value cannot contain a zero byte, so redacted values cannot be confused with real values.
+	HEADER_REDACTION_MARKER = 0
+
+	HEADER_COMMITMENT_SHA256 = 1 // commitment algorithm byte, which follows the marker in a redacted value - SHA-256 of the salt and the value
+
+	HEADER_REDACTION_MIN_SALT_LENGTH = 16
+)
+
+// length of a redacted value - marker, commitment algorithm and SHA-256 digest
+const redactedHeaderValueLength = 2 + sha256.Size
+
+// HeaderRedactionPolicy selects the headers, whose values are replaced by commitments in EncodeHeadersWithOptions. The names are compared ignoring case.
+type HeaderRedactionPolicy struct {
+	// Denylist - the headers are always redacted, e.g. "Authorization"
+	Redact []string
+	// Allowlist - if not empty, all headers, which are not in the list, are redacted
+	Allow []string
+}
+
+func containsHeaderName(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldRedact reports whether the value of the header with the given name must be redacted
+func (p *HeaderRedactionPolicy) ShouldRedact(name string) bool {
+	if containsHeaderName(p.Redact, name) {
+		return true
+	}
+	return len(p.Allow) != 0 && !containsHeaderName(p.Allow, name)
+}
+
+// RedactHeaderValue returns a redacted header value - HEADER_REDACTION_MARKER, HEADER_COMMITMENT_SHA256 and SHA-256 of the salt followed by the value.
+// The salt must be kept secret, otherwise low-entropy values can be recovered from the commitment by brute force.
+func RedactHeaderValue(value string, salt []byte) string {
+	hash := sha256.New()
+	hash.Write(salt)
+	hash.Write([]byte(value))
+
+	redacted := make([]byte, 0, redactedHeaderValueLength)
+	redacted = append(redacted, HEADER_REDACTION_MARKER, HEADER_COMMITMENT_SHA256)
+	redacted = hash.Sum(redacted)
+
+	return string(redacted)
+}
+
+// IsRedacted reports whether the header value has the format of a value redacted with RedactHeaderValue. The result can only be trusted
+// for headers encoded with EncodeHeadersWithOptions, which rejects values with HEADER_REDACTION_MARKER. EncodeHeaders, EncodeHeaderList and
+// EncodeHTTPHeaders don't validate values, so any value with the marker and the commitment algorithm prefix and the length of a commitment is reported as redacted.
+func (h Header) IsRedacted() bool {
+	return len(h.Value) == redactedHeaderValueLength && h.Value[0] == HEADER_REDACTION_MARKER && h.Value[1] == HEADER_COMMITMENT_SHA256
+}
+
+// Commitment returns the SHA-256 commitment of a redacted header value, or nil if the header is not redacted. See IsRedacted.
+func (h Header) Commitment() []byte {
+	if !h.IsRedacted() {
+		return nil
+	}
+	return []byte(h.Value[2:])
+}
+
+// VerifyRedacted reports whether the header was redacted and its commitment matches the given original value and salt
+func (h Header) VerifyRedacted(value string, salt []byte) bool {
+	if !h.IsRedacted() {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(h.Value), []byte(RedactHeaderValue(value, salt))) == 1
+}
+
+// RedactedHeaderNames returns the names of redacted headers in the list in the order of the list
+func RedactedHeaderNames(headers []Header) []string {
+	var names []string
+	for _, header := range headers {
+		if header.IsRedacted() {
+			names = append(names, header.Name)
+		}
+	}
+	return names
+}
+
+// checks that the salt is long enough to protect redacted values
+func validateRedactionSalt(salt []byte) error {
+	if len(salt) < HEADER_REDACTION_MIN_SALT_LENGTH {
+		return ErrEncodingHeadersRedactionSaltTooShort
+	}
+	return nil
+}
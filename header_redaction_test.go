@@ -0,0 +1,137 @@
+package aleoOracleEncoding
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHeaderRedactionPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy HeaderRedactionPolicy
+		header string
+		want   bool
+	}{
+		{name: "empty policy", header: "Authorization", want: false},
+		{name: "denylist", policy: HeaderRedactionPolicy{Redact: []string{"Authorization"}}, header: "authorization", want: true},
+		{name: "not in denylist", policy: HeaderRedactionPolicy{Redact: []string{"Authorization"}}, header: "Accept", want: false},
+		{name: "allowlist", policy: HeaderRedactionPolicy{Allow: []string{"Accept"}}, header: "ACCEPT", want: false},
+		{name: "not in allowlist", policy: HeaderRedactionPolicy{Allow: []string{"Accept"}}, header: "X-Api-Key", want: true},
+		{name: "in both lists", policy: HeaderRedactionPolicy{Allow: []string{"Accept"}, Redact: []string{"Accept"}}, header: "Accept", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.ShouldRedact(tt.header); got != tt.want {
+				t.Errorf("HeaderRedactionPolicy.ShouldRedact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactHeaderValue(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	redacted := RedactHeaderValue("Bearer token", salt)
+
+	digest := sha256.Sum256(append(append([]byte{}, salt...), "Bearer token"...))
+	want := string(append([]byte{HEADER_REDACTION_MARKER, HEADER_COMMITMENT_SHA256}, digest[:]...))
+	if redacted != want {
+		t.Fatalf("RedactHeaderValue() = %x, want %x", redacted, want)
+	}
+
+	header := Header{Name: "Authorization", Value: redacted}
+	if !header.IsRedacted() {
+		t.Error("Header.IsRedacted() = false, want true")
+	}
+	if !reflect.DeepEqual(header.Commitment(), digest[:]) {
+		t.Errorf("Header.Commitment() = %x, want %x", header.Commitment(), digest)
+	}
+	if !header.VerifyRedacted("Bearer token", salt) {
+		t.Error("Header.VerifyRedacted() = false for the original value")
+	}
+	if header.VerifyRedacted("Bearer other", salt) {
+		t.Error("Header.VerifyRedacted() = true for a different value")
+	}
+	if header.VerifyRedacted("Bearer token", []byte("fedcba9876543210")) {
+		t.Error("Header.VerifyRedacted() = true for a different salt")
+	}
+
+	plain := Header{Name: "Authorization", Value: "Bearer token"}
+	if plain.IsRedacted() || plain.Commitment() != nil || plain.VerifyRedacted("Bearer token", salt) {
+		t.Error("plain header is reported as redacted")
+	}
+}
+
+func TestEncodeHeadersWithRedaction(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	headers := []Header{
+		{Name: "authorization", Value: "Bearer token"},
+		{Name: "Accept", Value: "application/json"},
+		{Name: "X-Api-Key", Value: "secret"},
+	}
+	options := &HeaderEncodingOptions{
+		Canonicalization: HEADER_CANONICALIZATION_MIME,
+		Redaction:        &HeaderRedactionPolicy{Redact: []string{"Authorization"}, Allow: []string{"Accept"}},
+		RedactionSalt:    salt,
+	}
+
+	encoded, err := EncodeHeadersWithOptions(headers, options)
+	if err != nil {
+		t.Fatalf("EncodeHeadersWithOptions() error = %v", err)
+	}
+
+	decoded, err := DecodeHeaderList(encoded)
+	if err != nil {
+		t.Fatalf("DecodeHeaderList() error = %v", err)
+	}
+
+	if names := RedactedHeaderNames(decoded); !reflect.DeepEqual(names, []string{"Authorization", "X-Api-Key"}) {
+		t.Errorf("RedactedHeaderNames() = %v, want [Authorization X-Api-Key]", names)
+	}
+	if decoded[0].Value != "application/json" {
+		t.Errorf("allowed header value = %q, want %q", decoded[0].Value, "application/json")
+	}
+	if !decoded[1].VerifyRedacted("Bearer token", salt) || !decoded[2].VerifyRedacted("secret", salt) {
+		t.Error("Header.VerifyRedacted() = false for a decoded redacted header")
+	}
+
+	t.Run("short salt", func(t *testing.T) {
+		_, err := EncodeHeadersWithOptions(headers, &HeaderEncodingOptions{Redaction: options.Redaction, RedactionSalt: salt[:8]})
+		if !errors.Is(err, ErrEncodingHeadersRedactionSaltTooShort) {
+			t.Errorf("EncodeHeadersWithOptions() error = %v, want %v", err, ErrEncodingHeadersRedactionSaltTooShort)
+		}
+	})
+
+	t.Run("invalid value is rejected before redaction", func(t *testing.T) {
+		_, err := EncodeHeadersWithOptions([]Header{{Name: "Authorization", Value: "a\nb"}}, options)
+		if !errors.Is(err, ErrEncodingHeadersInvalidValue) {
+			t.Errorf("EncodeHeadersWithOptions() error = %v, want %v", err, ErrEncodingHeadersInvalidValue)
+		}
+	})
+	t.Run("entry length is checked after redaction", func(t *testing.T) {
+		long := []Header{{Name: "Authorization", Value: strings.Repeat("a", math.MaxUint16)}}
+		if _, err := EncodeHeadersWithOptions(long, nil); !errors.Is(err, ErrEncodingHeadersEntryTooLong) {
+			t.Errorf("EncodeHeadersWithOptions() error = %v, want %v", err, ErrEncodingHeadersEntryTooLong)
+		}
+
+		encoded, err := EncodeHeadersWithOptions(long, options)
+		if err != nil {
+			t.Fatalf("EncodeHeadersWithOptions() error = %v", err)
+		}
+		decoded, err := DecodeHeaderList(encoded)
+		if err != nil || len(decoded) != 1 || !decoded[0].VerifyRedacted(long[0].Value, salt) {
+			t.Errorf("DecodeHeaderList() = %v, %v, want a redacted header", decoded, err)
+		}
+	})
+
+	t.Run("marker is rejected in unredacted values", func(t *testing.T) {
+		fake := string([]byte{HEADER_REDACTION_MARKER, HEADER_COMMITMENT_SHA256}) + strings.Repeat("a", sha256.Size)
+		_, err := EncodeHeadersWithOptions([]Header{{Name: "Accept", Value: fake}}, options)
+		if !errors.Is(err, ErrEncodingHeadersInvalidValue) {
+			t.Errorf("EncodeHeadersWithOptions() error = %v, want %v", err, ErrEncodingHeadersInvalidValue)
+		}
+	})
+}
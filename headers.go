@@ -0,0 +1,334 @@
+package aleoOracleEncoding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrEncodingHeadersInvalidName             = errors.New("header name is not a valid RFC 7230 token")
+	ErrEncodingHeadersInvalidValue            = errors.New("header value is not a valid RFC 7230 field value")
+	ErrEncodingHeadersDuplicateHeader         = errors.New("header name is used more than once")
+	ErrEncodingHeadersEntryTooLong            = errors.New("header entry is longer than 65535 bytes")
+	ErrEncodingHeadersTooLong                 = errors.New("encoded headers are longer than 65535 bytes")
+	ErrEncodingHeadersCanonicalizationUnknown = errors.New("unknown header name canonicalization")
+)
+
+// HeaderCanonicalization defines how header names are transformed before encoding
+type HeaderCanonicalization int
+
+const (
+	HEADER_CANONICALIZATION_NONE      HeaderCanonicalization = iota // header names are encoded as they are
+	HEADER_CANONICALIZATION_LOWERCASE                               // header names are converted to lower case, e.g. "content-type"
+	HEADER_CANONICALIZATION_MIME                                    // header names are converted to the canonical MIME format, e.g. "Content-Type"
+)
+
+// HeaderEncodingOptions configures EncodeHeadersWithOptions
+type HeaderEncodingOptions struct {
+	Canonicalization HeaderCanonicalization
+	// Allows multiple entries with the same name after canonicalization. Names that differ only by case are always rejected.
+	AllowDuplicates bool
+	// Selects the headers, whose values are replaced by commitments, see RedactHeaderValue. Nothing is redacted if nil.
+	Redaction *HeaderRedactionPolicy
+	// Secret salt of the commitments, must be at least HEADER_REDACTION_MIN_SALT_LENGTH bytes if Redaction is set
+	RedactionSalt []byte
+}
+
+// Header is one request header entry. Unlike a map of headers, a list of headers can contain multiple entries with the same name.
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Encodes a list of headers in the same format as EncodeHeaders, every entry is encoded separately. The headers are not validated,
+// use EncodeHeadersWithOptions to validate them.
+//
+// The headers are sorted by name using a stable sort, so the entries with the same name keep their order in the list. For headers
+// with unique names the result is the same as the result of EncodeHeaders.
+func EncodeHeaderList(headers []Header) []byte {
+	buf := make([]byte, TARGET_ALIGNMENT, len(headers)*TARGET_ALIGNMENT+TARGET_ALIGNMENT)
+
+	sorted := make([]Header, len(headers))
+	copy(sorted, headers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	for _, header := range sorted {
+		entry := []byte(fmt.Sprintf("%s:%s", header.Name, header.Value))
+		lenBuf := make([]byte, 2)
+		binary.LittleEndian.PutUint16(lenBuf, uint16(len(entry)))
+		entry = append(lenBuf, entry...)
+
+		padding := getPadding(entry, TARGET_ALIGNMENT)
+
+		buf = append(buf, entry...)
+		buf = append(buf, padding...)
+	}
+
+	numHeaders := uint64(len(headers))
+	copy(buf[:TARGET_ALIGNMENT/2], NumberToBytes(numHeaders))
+
+	// the first block is the one where we're writing length so we're not counting it
+	numBlocks := uint64(len(buf)/TARGET_ALIGNMENT - 1)
+	copy(buf[TARGET_ALIGNMENT/2:TARGET_ALIGNMENT], NumberToBytes(numBlocks))
+
+	return buf
+}
+
+// Encodes HTTP headers using EncodeHeaderList. Every value of a header is encoded as a separate entry, the values of a header keep their order.
+// The names are encoded as they are in the map, without canonicalization.
+func EncodeHTTPHeaders(headers http.Header) []byte {
+	list := make([]Header, 0, len(headers))
+	for name, values := range headers {
+		for _, value := range values {
+			list = append(list, Header{Name: name, Value: value})
+		}
+	}
+
+	return EncodeHeaderList(list)
+}
+
+// Decodes headers created with EncodeHeaders, EncodeHeaderList or EncodeHTTPHeaders. The entries are returned in the encoded order,
+// entries with the same name are preserved.
+func DecodeHeaderList(buf []byte) ([]Header, error) {
+	if len(buf) < TARGET_ALIGNMENT {
+		return nil, ErrDecodingBufferTooShort
+	}
+
+	headers := make([]Header, 0)
+
+	// if there's only one block, then it's a block header with no content
+	if len(buf) == TARGET_ALIGNMENT {
+		return headers, nil
+	}
+
+	parsedBlockHeader := BlockToNumbers(buf[:TARGET_ALIGNMENT])
+	if len(parsedBlockHeader) != 2 {
+		return nil, ErrDecodingHeadersInvalidBlockHeader
+	}
+
+	headerCount := parsedBlockHeader[0]
+	blockCount := parsedBlockHeader[1]
+
+	// verify that the encoded block length + block header matches the buffer length
+	if len(buf)%TARGET_ALIGNMENT != 0 || blockCount != uint64(len(buf)/TARGET_ALIGNMENT-1) {
+		return nil, ErrDecodingHeadersCountLengthMismatch
+	}
+
+	// every entry takes at least 1 block
+	if headerCount > blockCount {
+		return nil, ErrDecodingHeadersCountProcessedMismatch
+	}
+
+	byteOffset := TARGET_ALIGNMENT
+	for byteOffset < len(buf) {
+		if byteOffset+2 > len(buf) {
+			return nil, ErrDecodingHeadersInvalidHeaderLength
+		}
+
+		// read 2 bytes of header length and convert it to a number
+		entryLenBuf := buf[byteOffset : byteOffset+2]
+		byteOffset += 2
+
+		// decode the length of an entry
+		entryLen := int(binary.LittleEndian.Uint16(entryLenBuf))
+		if byteOffset+entryLen > len(buf) {
+			return nil, ErrDecodingHeadersInvalidHeaderLength
+		}
+
+		// get the entry
+		entry := buf[byteOffset : byteOffset+entryLen]
+		byteOffset += entryLen
+
+		// an entry is formatted as "header:value", split around the first colon
+		name, value, found := strings.Cut(string(entry), ":")
+		if !found {
+			return nil, ErrDecodingHeadersInvalidHeaderEncoding
+		}
+		if name == "" {
+			return nil, ErrDecodingHeadersEmptyHeader
+		}
+
+		headers = append(headers, Header{Name: name, Value: value})
+
+		// check if this header entry was padded, skip the padding if it was
+		currentAlignment := byteOffset % TARGET_ALIGNMENT
+		if currentAlignment != 0 {
+			paddingBytes := TARGET_ALIGNMENT - currentAlignment
+			if byteOffset+paddingBytes > len(buf) {
+				return nil, ErrDecodingHeadersInvalidHeaderLength
+			}
+			padding := buf[byteOffset : byteOffset+paddingBytes]
+			expectedPadding := make([]byte, paddingBytes)
+			// may be an overkill to verify that the padding used is made of zeroes?
+			if !bytes.Equal(padding, expectedPadding) {
+				return nil, ErrDecodingUnexpectedPadding
+			}
+
+			byteOffset += paddingBytes
+		}
+	}
+
+	// verify that we've got the same number of headers as the encoding say
+	if len(headers) != int(headerCount) {
+		return nil, ErrDecodingHeadersCountProcessedMismatch
+	}
+
+	return headers, nil
+}
+
+// Decodes headers created with EncodeHeaders, EncodeHeaderList or EncodeHTTPHeaders to HTTP headers. The names are used as they are encoded,
+// without canonicalization. The values of entries with the same name are collected in the encoded order.
+func DecodeHTTPHeaders(buf []byte) (http.Header, error) {
+	list, err := DecodeHeaderList(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(http.Header, len(list))
+	for _, header := range list {
+		headers[header.Name] = append(headers[header.Name], header.Value)
+	}
+
+	return headers, nil
+}
+
+// DuplicateHeaderNames returns the names, which appear in the list of headers more than once, in the order of their first appearance.
+// The names are compared as they are, without canonicalization.
+func DuplicateHeaderNames(headers []Header) []string {
+	seen := make(map[string]int, len(headers))
+	var duplicates []string
+
+	for _, header := range headers {
+		seen[header.Name]++
+		if seen[header.Name] == 2 {
+			duplicates = append(duplicates, header.Name)
+		}
+	}
+
+	return duplicates
+}
+
+// reports whether c is a tchar as defined in RFC 7230, section 3.2.6
+func isTokenChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1
+	}
+}
+
+// reports whether name is a token as defined in RFC 7230, section 3.2.6
+func isValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isTokenChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// reports whether value is a field-value without obs-fold as defined in RFC 7230, section 3.2. Visible characters, obs-text,
+// spaces and horizontal tabs are allowed, the value cannot start or end with whitespace.
+func isValidHeaderValue(value string) bool {
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		isWhitespace := c == ' ' || c == '\t'
+		if isWhitespace && (i == 0 || i == len(value)-1) {
+			return false
+		}
+		if !isWhitespace && (c < 0x21 || c == 0x7f) {
+			return false
+		}
+	}
+	return true
+}
+
+func canonicalizeHeaderName(name string, canonicalization HeaderCanonicalization) (string, error) {
+	switch canonicalization {
+	case HEADER_CANONICALIZATION_NONE:
+		return name, nil
+	case HEADER_CANONICALIZATION_LOWERCASE:
+		return strings.ToLower(name), nil
+	case HEADER_CANONICALIZATION_MIME:
+		return textproto.CanonicalMIMEHeaderKey(name), nil
+	default:
+		return "", ErrEncodingHeadersCanonicalizationUnknown
+	}
+}
+
+// Encodes a list of headers in the same format as EncodeHeaderList after canonicalizing and validating them. If options is nil, the default options are used -
+// no canonicalization and no duplicates.
+//
+// The names must be RFC 7230 tokens, so they cannot contain a colon, and the values must be RFC 7230 field values without leading or trailing whitespace.
+// Names, which are equal ignoring case after canonicalization, are rejected unless they are exactly the same and options.AllowDuplicates is set.
+// Every encoded "name:value" entry and the whole encoded headers must fit into 65535 bytes, since the lengths are encoded as 2 bytes.
+//
+// If options.Redaction is set, the values of the selected headers are validated and replaced by commitments using RedactHeaderValue with options.RedactionSalt.
+// Use Header.IsRedacted on decoded headers to find redacted headers, and Header.VerifyRedacted to verify them with the original value and the salt.
+// Values with a zero byte are invalid, so a value encoded by this function looks like a redacted value only if it was redacted.
+func EncodeHeadersWithOptions(headers []Header, options *HeaderEncodingOptions) ([]byte, error) {
+	if options == nil {
+		options = &HeaderEncodingOptions{}
+	}
+
+	if options.Redaction != nil {
+		if err := validateRedactionSalt(options.RedactionSalt); err != nil {
+			return nil, err
+		}
+	}
+
+	canonicalized := make([]Header, 0, len(headers))
+	// canonicalized names by their lower case version
+	names := make(map[string]string, len(headers))
+
+	for _, header := range headers {
+		if !isValidHeaderName(header.Name) {
+			return nil, fmt.Errorf("%w: %q", ErrEncodingHeadersInvalidName, header.Name)
+		}
+		if !isValidHeaderValue(header.Value) {
+			return nil, fmt.Errorf("%w: %s", ErrEncodingHeadersInvalidValue, header.Name)
+		}
+		name, err := canonicalizeHeaderName(header.Name, options.Canonicalization)
+		if err != nil {
+			return nil, err
+		}
+
+		lowerName := strings.ToLower(name)
+		if existing, ok := names[lowerName]; ok && (existing != name || !options.AllowDuplicates) {
+			return nil, fmt.Errorf("%w: %s", ErrEncodingHeadersDuplicateHeader, name)
+		}
+		names[lowerName] = name
+
+		value := header.Value
+		if options.Redaction != nil && options.Redaction.ShouldRedact(name) {
+			value = RedactHeaderValue(value, options.RedactionSalt)
+		}
+
+		// the length of the encoded entry, so a long value fits if it's redacted
+		if len(name)+1+len(value) > math.MaxUint16 {
+			return nil, fmt.Errorf("%w: %s", ErrEncodingHeadersEntryTooLong, name)
+		}
+
+		canonicalized = append(canonicalized, Header{Name: name, Value: value})
+	}
+
+	buf := EncodeHeaderList(canonicalized)
+	if len(buf) > math.MaxUint16 {
+		return nil, ErrEncodingHeadersTooLong
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,259 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeHeaderList(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []Header
+		want    []byte
+	}{
+		{
+			name:    "no headers",
+			headers: nil,
+			want:    []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name:    "repeated header keeps the order of values",
+			headers: []Header{{Name: "c", Value: "2"}, {Name: "a", Value: "b"}, {Name: "c", Value: "1"}},
+			want: []byte{
+				3, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0,
+				3, 0, 0x61, 0x3a, 0x62, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				3, 0, 0x63, 0x3a, 0x32, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+				3, 0, 0x63, 0x3a, 0x31, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EncodeHeaderList(tt.headers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EncodeHeaderList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("same as EncodeHeaders for unique names", func(t *testing.T) {
+		headers := map[string]string{"b": "2", "a": "1", "Accept": "*/*"}
+		list := []Header{{Name: "b", Value: "2"}, {Name: "a", Value: "1"}, {Name: "Accept", Value: "*/*"}}
+
+		if got, want := EncodeHeaderList(list), EncodeHeaders(headers); !reflect.DeepEqual(got, want) {
+			t.Errorf("EncodeHeaderList() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("doesn't modify the input", func(t *testing.T) {
+		list := []Header{{Name: "b", Value: "2"}, {Name: "a", Value: "1"}}
+		EncodeHeaderList(list)
+
+		if list[0].Name != "b" {
+			t.Errorf("EncodeHeaderList() modified the input: %v", list)
+		}
+	})
+}
+
+func TestDecodeHeaderList(t *testing.T) {
+	headers := []Header{
+		{Name: "Cookie", Value: "a=1"},
+		{Name: "Accept", Value: "text/html"},
+		{Name: "Cookie", Value: "b=2"},
+		{Name: "Accept", Value: "application/json"},
+	}
+	want := []Header{
+		{Name: "Accept", Value: "text/html"},
+		{Name: "Accept", Value: "application/json"},
+		{Name: "Cookie", Value: "a=1"},
+		{Name: "Cookie", Value: "b=2"},
+	}
+
+	encoded := EncodeHeaderList(headers)
+
+	got, err := DecodeHeaderList(encoded)
+	if err != nil {
+		t.Fatalf("DecodeHeaderList() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeHeaderList() = %v, want %v", got, want)
+	}
+
+	if reencoded := EncodeHeaderList(got); !reflect.DeepEqual(reencoded, encoded) {
+		t.Errorf("EncodeHeaderList() after DecodeHeaderList() = %v, want %v", reencoded, encoded)
+	}
+
+	if duplicates := DuplicateHeaderNames(got); !reflect.DeepEqual(duplicates, []string{"Accept", "Cookie"}) {
+		t.Errorf("DuplicateHeaderNames() = %v, want [Accept Cookie]", duplicates)
+	}
+
+	t.Run("DecodeHeaders rejects duplicates", func(t *testing.T) {
+		_, err := DecodeHeaders(encoded)
+		if !errors.Is(err, ErrDecodingHeadersDuplicateHeader) {
+			t.Errorf("DecodeHeaders() error = %v, want %v", err, ErrDecodingHeadersDuplicateHeader)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		got, err := DecodeHeaderList(EncodeHeaderList(nil))
+		if err != nil || len(got) != 0 {
+			t.Errorf("DecodeHeaderList() = %v, %v, want no headers", got, err)
+		}
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		if _, err := DecodeHeaderList(encoded[:8]); !errors.Is(err, ErrDecodingBufferTooShort) {
+			t.Errorf("DecodeHeaderList() error = %v, want %v", err, ErrDecodingBufferTooShort)
+		}
+	})
+}
+
+func TestHTTPHeaders(t *testing.T) {
+	headers := http.Header{
+		"Accept":     {"text/html", "application/json"},
+		"User-Agent": {"node"},
+		"x-custom":   {"1"},
+	}
+
+	encoded := EncodeHTTPHeaders(headers)
+
+	list, err := DecodeHeaderList(encoded)
+	if err != nil {
+		t.Fatalf("DecodeHeaderList() error = %v", err)
+	}
+	wantList := []Header{
+		{Name: "Accept", Value: "text/html"},
+		{Name: "Accept", Value: "application/json"},
+		{Name: "User-Agent", Value: "node"},
+		{Name: "x-custom", Value: "1"},
+	}
+	if !reflect.DeepEqual(list, wantList) {
+		t.Errorf("DecodeHeaderList() = %v, want %v", list, wantList)
+	}
+
+	decoded, err := DecodeHTTPHeaders(encoded)
+	if err != nil {
+		t.Fatalf("DecodeHTTPHeaders() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, headers) {
+		t.Errorf("DecodeHTTPHeaders() = %v, want %v", decoded, headers)
+	}
+}
+
+func TestEncodeHeadersWithOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []Header
+		options *HeaderEncodingOptions
+		want    []Header
+		wantErr error
+	}{
+		{
+			name:    "default options",
+			headers: []Header{{Name: "X-Custom", Value: "a b"}, {Name: "Accept", Value: ""}},
+			want:    []Header{{Name: "Accept", Value: ""}, {Name: "X-Custom", Value: "a b"}},
+		},
+		{
+			name:    "lowercase",
+			headers: []Header{{Name: "X-Custom", Value: "1"}, {Name: "Accept", Value: "*/*"}},
+			options: &HeaderEncodingOptions{Canonicalization: HEADER_CANONICALIZATION_LOWERCASE},
+			want:    []Header{{Name: "accept", Value: "*/*"}, {Name: "x-custom", Value: "1"}},
+		},
+		{
+			name:    "MIME",
+			headers: []Header{{Name: "x-custom", Value: "1"}, {Name: "content-TYPE", Value: "text/plain"}},
+			options: &HeaderEncodingOptions{Canonicalization: HEADER_CANONICALIZATION_MIME},
+			want:    []Header{{Name: "Content-Type", Value: "text/plain"}, {Name: "X-Custom", Value: "1"}},
+		},
+		{
+			name:    "allowed duplicates after canonicalization",
+			headers: []Header{{Name: "cookie", Value: "a=1"}, {Name: "Cookie", Value: "b=2"}},
+			options: &HeaderEncodingOptions{Canonicalization: HEADER_CANONICALIZATION_MIME, AllowDuplicates: true},
+			want:    []Header{{Name: "Cookie", Value: "a=1"}, {Name: "Cookie", Value: "b=2"}},
+		},
+		{
+			name:    "names differing by case",
+			headers: []Header{{Name: "accept", Value: "a"}, {Name: "Accept", Value: "b"}},
+			options: &HeaderEncodingOptions{AllowDuplicates: true},
+			wantErr: ErrEncodingHeadersDuplicateHeader,
+		},
+		{
+			name:    "duplicates",
+			headers: []Header{{Name: "Accept", Value: "a"}, {Name: "Accept", Value: "b"}},
+			wantErr: ErrEncodingHeadersDuplicateHeader,
+		},
+		{
+			name:    "duplicates after canonicalization",
+			headers: []Header{{Name: "accept", Value: "a"}, {Name: "Accept", Value: "b"}},
+			options: &HeaderEncodingOptions{Canonicalization: HEADER_CANONICALIZATION_LOWERCASE},
+			wantErr: ErrEncodingHeadersDuplicateHeader,
+		},
+		{
+			name:    "name with a colon",
+			headers: []Header{{Name: "a:b", Value: "c"}},
+			wantErr: ErrEncodingHeadersInvalidName,
+		},
+		{
+			name:    "empty name",
+			headers: []Header{{Name: "", Value: "c"}},
+			wantErr: ErrEncodingHeadersInvalidName,
+		},
+		{
+			name:    "name with a space",
+			headers: []Header{{Name: "User Agent", Value: "c"}},
+			wantErr: ErrEncodingHeadersInvalidName,
+		},
+		{
+			name:    "value with a new line",
+			headers: []Header{{Name: "a", Value: "b\r\nc: d"}},
+			wantErr: ErrEncodingHeadersInvalidValue,
+		},
+		{
+			name:    "value with leading whitespace",
+			headers: []Header{{Name: "a", Value: " b"}},
+			wantErr: ErrEncodingHeadersInvalidValue,
+		},
+		{
+			name:    "value with trailing whitespace",
+			headers: []Header{{Name: "a", Value: "b\t"}},
+			wantErr: ErrEncodingHeadersInvalidValue,
+		},
+		{
+			name:    "too long entry",
+			headers: []Header{{Name: "a", Value: strings.Repeat("b", 65534)}},
+			wantErr: ErrEncodingHeadersEntryTooLong,
+		},
+		{
+			name:    "too long headers",
+			headers: []Header{{Name: "a", Value: strings.Repeat("b", 40000)}, {Name: "c", Value: strings.Repeat("d", 40000)}},
+			wantErr: ErrEncodingHeadersTooLong,
+		},
+		{
+			name:    "unknown canonicalization",
+			headers: []Header{{Name: "a", Value: "b"}},
+			options: &HeaderEncodingOptions{Canonicalization: 10},
+			wantErr: ErrEncodingHeadersCanonicalizationUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeHeadersWithOptions(tt.headers, tt.options)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EncodeHeadersWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			decoded, err := DecodeHeaderList(got)
+			if err != nil {
+				t.Fatalf("DecodeHeaderList() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.want) {
+				t.Errorf("EncodeHeadersWithOptions() encoded %v, want %v", decoded, tt.want)
+			}
+		})
+	}
+}
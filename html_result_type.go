@@ -0,0 +1,111 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrHtmlAttributeNameInvalid = errors.New("HTML attribute name is empty or contains invalid characters")
+)
+
+const (
+	HTML_RESULT_TYPE_ATTRIBUTE_VALUE = 3 // value used for encoding HTML result type for Aleo
+
+	// Result type for the value of an attribute of the selected element. The attribute name is appended after a colon, e.g. "attribute:href",
+	// see HtmlAttributeResultType.
+	HTML_RESULT_TYPE_ATTRIBUTE = "attribute"
+)
+
+// HtmlAttributeResultType returns the HTML result type selecting the value of the attribute with the given name, e.g. "attribute:href"
+func HtmlAttributeResultType(attribute string) string {
+	return HTML_RESULT_TYPE_ATTRIBUTE + ":" + attribute
+}
+
+// SplitHtmlResultType splits an HTML result type into the type and the attribute name. The attribute name is empty for types other than HTML_RESULT_TYPE_ATTRIBUTE.
+func SplitHtmlResultType(resultType string) (string, string) {
+	if name, attribute, found := strings.Cut(resultType, ":"); found && name == HTML_RESULT_TYPE_ATTRIBUTE {
+		return name, attribute
+	}
+	return resultType, ""
+}
+
+// reports whether name is a valid HTML attribute name - not empty, without whitespace, control characters, quotes, '>', '/' and '='
+func isValidHtmlAttributeName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, char := range name {
+		if char <= ' ' || char == 0x7f || strings.ContainsRune("\"'>/=", char) {
+			return false
+		}
+	}
+	return true
+}
+
+// checks that the HTML result type is known and the attribute name of the attribute type is valid
+func validateHtmlResultType(resultType string) error {
+	switch name, attribute := SplitHtmlResultType(resultType); name {
+	case HTML_RESULT_TYPE_ELEMENT, HTML_RESULT_TYPE_VALUE:
+		return nil
+	case HTML_RESULT_TYPE_ATTRIBUTE:
+		if !isValidHtmlAttributeName(attribute) {
+			return ErrHtmlAttributeNameInvalid
+		}
+		return nil
+	default:
+		return ErrHtmlResultTypeUnknown
+	}
+}
+
+// encodes the HTML result type as 1 block with the type in the first byte. The attribute type is followed by 1 block with the length of the attribute name
+// in the first 8 bytes and the attribute name padded to TARGET_ALIGNMENT.
+func encodeHtmlResultType(resultType string) ([]byte, error) {
+	if err := validateHtmlResultType(resultType); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, TARGET_ALIGNMENT)
+	name, attribute := SplitHtmlResultType(resultType)
+	switch name {
+	case HTML_RESULT_TYPE_ELEMENT:
+		buf[0] = HTML_RESULT_TYPE_ELEMENT_VALUE
+	case HTML_RESULT_TYPE_VALUE:
+		buf[0] = HTML_RESULT_TYPE_VALUE_VALUE
+	case HTML_RESULT_TYPE_ATTRIBUTE:
+		buf[0] = HTML_RESULT_TYPE_ATTRIBUTE_VALUE
+
+		lengthBuf := make([]byte, TARGET_ALIGNMENT)
+		copy(lengthBuf, NumberToBytes(uint64(len(attribute))))
+		buf = append(buf, lengthBuf...)
+		buf = append(buf, attribute...)
+		buf = append(buf, getPadding([]byte(attribute), TARGET_ALIGNMENT)...)
+	}
+
+	return buf, nil
+}
+
+// decodes the HTML result type starting at offset, returns the type and the offset after it
+func decodeHtmlResultType(buf []byte, offset int) (string, int, error) {
+	if offset+TARGET_ALIGNMENT > len(buf) {
+		return "", 0, ErrDecodingBufferTooShort
+	}
+
+	switch buf[offset] {
+	case HTML_RESULT_TYPE_ELEMENT_VALUE:
+		return HTML_RESULT_TYPE_ELEMENT, offset + TARGET_ALIGNMENT, nil
+	case HTML_RESULT_TYPE_VALUE_VALUE:
+		return HTML_RESULT_TYPE_VALUE, offset + TARGET_ALIGNMENT, nil
+	case HTML_RESULT_TYPE_ATTRIBUTE_VALUE:
+		attribute, next, err := readLengthPrefixed(buf, offset+TARGET_ALIGNMENT)
+		if err != nil {
+			return "", 0, err
+		}
+		if !isValidHtmlAttributeName(string(attribute)) {
+			return "", 0, ErrHtmlAttributeNameInvalid
+		}
+		return HtmlAttributeResultType(string(attribute)), next, nil
+	default:
+		return "", 0, ErrHtmlResultTypeUnknown
+	}
+}
@@ -0,0 +1,102 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHtmlAttributeResultType(t *testing.T) {
+	resultType := HtmlAttributeResultType("data-price")
+	if resultType != "attribute:data-price" {
+		t.Fatalf("HtmlAttributeResultType() = %q", resultType)
+	}
+	if name, attribute := SplitHtmlResultType(resultType); name != HTML_RESULT_TYPE_ATTRIBUTE || attribute != "data-price" {
+		t.Errorf("SplitHtmlResultType() = %q, %q", name, attribute)
+	}
+	if name, attribute := SplitHtmlResultType(HTML_RESULT_TYPE_VALUE); name != HTML_RESULT_TYPE_VALUE || attribute != "" {
+		t.Errorf("SplitHtmlResultType() = %q, %q", name, attribute)
+	}
+
+	contentType := "text/plain"
+	encoded, err := EncodeOptionalFields(&resultType, &contentType, nil)
+	if err != nil {
+		t.Fatalf("EncodeOptionalFields() error = %v", err)
+	}
+
+	want := []byte{
+		OPTIONAL_FIELDS_HEADER_HAS_HTML_RESULT_TYPE | OPTIONAL_FIELDS_HEADER_HAS_CONTENT_TYPE, 0, 0, 0, 0, 0, 0, 0, 6, 0, 0, 0, 0, 0, 0, 0,
+		HTML_RESULT_TYPE_ATTRIBUTE_VALUE, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		// attribute name
+		10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		'd', 'a', 't', 'a', '-', 'p', 'r', 'i', 'c', 'e', 0, 0, 0, 0, 0, 0,
+		// content type
+		10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		't', 'e', 'x', 't', '/', 'p', 'l', 'a', 'i', 'n', 0, 0, 0, 0, 0, 0,
+		// request body
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	if !reflect.DeepEqual(encoded, want) {
+		t.Errorf("EncodeOptionalFields() = %v, want %v", encoded, want)
+	}
+
+	gotResultType, gotContentType, gotBody, err := DecodeOptionalFields(encoded)
+	if err != nil {
+		t.Fatalf("DecodeOptionalFields() error = %v", err)
+	}
+	if !compareStringPtr(gotResultType, &resultType) || !compareStringPtr(gotContentType, &contentType) || gotBody != nil {
+		t.Errorf("DecodeOptionalFields() = %v, %v, %v", gotResultType, gotContentType, gotBody)
+	}
+
+	fields, err := DecodeOptionalFieldMap(encoded)
+	if err != nil {
+		t.Fatalf("DecodeOptionalFieldMap() error = %v", err)
+	}
+	if fields[OPTIONAL_FIELD_HTML_RESULT_TYPE] != resultType {
+		t.Errorf("DecodeOptionalFieldMap() HTML result type = %v, want %s", fields[OPTIONAL_FIELD_HTML_RESULT_TYPE], resultType)
+	}
+
+	var kinds strings.Builder
+	for _, kind := range classifyOptionalFields(encoded)[2*TARGET_ALIGNMENT : 4*TARGET_ALIGNMENT] {
+		kinds.WriteString(kind.String()[:1])
+	}
+	if got := kinds.String(); got != "ddddddddrrrrrrrrddddddddddpppppp" {
+		t.Errorf("classifyOptionalFields() attribute name kinds = %s", got)
+	}
+}
+
+func TestHtmlAttributeResultTypeErrors(t *testing.T) {
+	for _, attribute := range []string{"", "data price", "a=b", "\"href\"", "a/b"} {
+		resultType := HtmlAttributeResultType(attribute)
+		if _, err := EncodeOptionalFields(&resultType, nil, nil); !errors.Is(err, ErrHtmlAttributeNameInvalid) {
+			t.Errorf("EncodeOptionalFields(%q) error = %v, want %v", resultType, err, ErrHtmlAttributeNameInvalid)
+		}
+	}
+
+	for _, resultType := range []string{HTML_RESULT_TYPE_ATTRIBUTE, "attribute:"} {
+		fields := OptionalFields{HtmlResultType: &resultType}
+		if err := fields.Validate("GET", RESPONSE_FORMAT_HTML); !errors.Is(err, ErrHtmlAttributeNameInvalid) {
+			t.Errorf("OptionalFields.Validate(%q) error = %v, want %v", resultType, err, ErrHtmlAttributeNameInvalid)
+		}
+	}
+
+	unknown := "element:href"
+	if _, err := EncodeOptionalFields(&unknown, nil, nil); !errors.Is(err, ErrHtmlResultTypeUnknown) {
+		t.Errorf("EncodeOptionalFields() error = %v, want %v", err, ErrHtmlResultTypeUnknown)
+	}
+
+	// the attribute name is longer than the buffer
+	truncated := []byte{
+		OPTIONAL_FIELDS_HEADER_HAS_HTML_RESULT_TYPE, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0,
+		HTML_RESULT_TYPE_ATTRIBUTE_VALUE, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		40, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		'h', 'r', 'e', 'f', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	if _, _, _, err := DecodeOptionalFields(truncated); err == nil {
+		t.Error("DecodeOptionalFields() expected an error for a truncated attribute name")
+	}
+	if _, err := DecodeOptionalFieldMap(truncated); err == nil {
+		t.Error("DecodeOptionalFieldMap() expected an error for a truncated attribute name")
+	}
+}
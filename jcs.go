@@ -0,0 +1,344 @@
+package aleoOracleEncoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	ErrJSONCanonicalizationInvalid      = errors.New("cannot canonicalize invalid JSON")
+	ErrJSONCanonicalizationDuplicateKey = errors.New("cannot canonicalize JSON object with duplicate keys")
+
+	ErrOptionalFieldsCanonicalizationNotJSON    = errors.New("request body canonicalization requires a request body with a JSON content type")
+	ErrDecodingOptionalsInvalidCanonicalization = errors.New("request body is marked as canonicalized, but it's not canonical JSON")
+)
+
+const (
+	OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY = 32 // bit flag used for encoding that the request body was canonicalized with CanonicalizeJSON for Aleo
+
+	// name of the request body canonicalization flag in OptionalFieldMap, the value is bool
+	OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY = "canonicalizeRequestBody"
+)
+
+// reports whether the content type is JSON - "application/json" or a type with "+json" suffix, parameters are ignored
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// returns the value of the request body canonicalization flag, false if the flag is not in the map
+func requestBodyCanonicalizationFlag(fields OptionalFieldMap) (bool, error) {
+	value, ok := fields[OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY]
+	if !ok {
+		return false, nil
+	}
+
+	canonicalize, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrOptionalFieldInvalidValue, OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY)
+	}
+	return canonicalize, nil
+}
+
+// checks that the decoded fields marked with OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY contain a request body or its commitment with a JSON content type,
+// and that the request body is canonical JSON. The body behind a commitment cannot be checked.
+func checkCanonicalRequestBody(fields OptionalFieldMap) error {
+	contentType, _ := fields[OPTIONAL_FIELD_REQUEST_CONTENT_TYPE].(string)
+	if !isJSONContentType(contentType) {
+		return ErrDecodingOptionalsInvalidCanonicalization
+	}
+
+	if _, ok := fields[OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT]; ok {
+		return nil
+	}
+
+	body, ok := fields[OPTIONAL_FIELD_REQUEST_BODY].(string)
+	if !ok {
+		return ErrDecodingOptionalsInvalidCanonicalization
+	}
+	canonical, err := CanonicalizeJSON([]byte(body))
+	if err != nil || string(canonical) != body {
+		return ErrDecodingOptionalsInvalidCanonicalization
+	}
+
+	return nil
+}
+
+// CanonicalizeJSON serializes JSON data using the JSON Canonicalization Scheme defined in RFC 8785. Whitespace is removed,
+// object keys are sorted by their UTF-16 code units, numbers are formatted like ECMAScript formats IEEE 754 doubles and strings use the minimal escaping.
+// The data must be I-JSON - valid UTF-8 without duplicate object keys.
+func CanonicalizeJSON(data []byte) ([]byte, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("%w: invalid UTF-8", ErrJSONCanonicalizationInvalid)
+	}
+
+	// encoding/json replaces invalid surrogates with U+FFFD, so different inputs would have the same canonical form
+	if err := validateJSONSurrogates(data); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var buf bytes.Buffer
+	if err := canonicalizeJSONValue(decoder, &buf); err != nil {
+		return nil, err
+	}
+
+	// only one value is allowed
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%w: unexpected data after the value", ErrJSONCanonicalizationInvalid)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// checks that every \u escape of a surrogate in JSON strings is a high surrogate followed by a \u escape of a low surrogate.
+// Other errors are left to the decoder
+func validateJSONSurrogates(data []byte) error {
+	// parses a \u escape at i, returns -1 if there is none
+	escapedRune := func(i int) rune {
+		if i+6 > len(data) || data[i] != '\\' || data[i+1] != 'u' {
+			return -1
+		}
+		value, err := strconv.ParseUint(string(data[i+2:i+6]), 16, 16)
+		if err != nil {
+			return -1
+		}
+		return rune(value)
+	}
+
+	inString := false
+	for i := 0; i < len(data); i++ {
+		switch {
+		case data[i] == '"':
+			inString = !inString
+		case inString && data[i] == '\\':
+			r := escapedRune(i)
+			if r == -1 {
+				// skip the escaped character
+				i++
+				continue
+			}
+
+			if utf16.IsSurrogate(r) {
+				if next := escapedRune(i + 6); r >= 0xdc00 || next < 0xdc00 || next > 0xdfff {
+					return fmt.Errorf("%w: unpaired surrogate at offset %d", ErrJSONCanonicalizationInvalid, i)
+				}
+				i += 6
+			}
+			i += 5
+		}
+	}
+
+	return nil
+}
+
+func canonicalizeJSONValue(decoder *json.Decoder, buf *bytes.Buffer) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrJSONCanonicalizationInvalid, err)
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		switch value {
+		case '{':
+			return canonicalizeJSONObject(decoder, buf)
+		case '[':
+			return canonicalizeJSONArray(decoder, buf)
+		default:
+			return fmt.Errorf("%w: unexpected %v", ErrJSONCanonicalizationInvalid, value)
+		}
+	case json.Number:
+		number, err := formatJSONNumber(value)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case string:
+		writeJSONString(buf, value)
+	case bool:
+		buf.WriteString(strconv.FormatBool(value))
+	case nil:
+		buf.WriteString("null")
+	}
+
+	return nil
+}
+
+func canonicalizeJSONArray(decoder *json.Decoder, buf *bytes.Buffer) error {
+	buf.WriteByte('[')
+	for i := 0; decoder.More(); i++ {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		if err := canonicalizeJSONValue(decoder, buf); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+
+	// the closing bracket
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("%w: %v", ErrJSONCanonicalizationInvalid, err)
+	}
+
+	return nil
+}
+
+func canonicalizeJSONObject(decoder *json.Decoder, buf *bytes.Buffer) error {
+	type member struct {
+		key   string
+		value []byte
+	}
+
+	var members []member
+	keys := make(map[string]bool)
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrJSONCanonicalizationInvalid, err)
+		}
+		key := token.(string)
+		if keys[key] {
+			return fmt.Errorf("%w: %q", ErrJSONCanonicalizationDuplicateKey, key)
+		}
+		keys[key] = true
+
+		var value bytes.Buffer
+		if err := canonicalizeJSONValue(decoder, &value); err != nil {
+			return err
+		}
+		members = append(members, member{key: key, value: value.Bytes()})
+	}
+
+	// the closing brace
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("%w: %v", ErrJSONCanonicalizationInvalid, err)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return compareUTF16(members[i].key, members[j].key) < 0
+	})
+
+	buf.WriteByte('{')
+	for i, member := range members {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONString(buf, member.key)
+		buf.WriteByte(':')
+		buf.Write(member.value)
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// compares strings by their UTF-16 code units
+func compareUTF16(a, b string) int {
+	unitsA, unitsB := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(unitsA) && i < len(unitsB); i++ {
+		if unitsA[i] != unitsB[i] {
+			return int(unitsA[i]) - int(unitsB[i])
+		}
+	}
+	return len(unitsA) - len(unitsB)
+}
+
+// writes a JSON string escaping only the quotation mark, the reverse solidus and control characters
+func writeJSONString(buf *bytes.Buffer, str string) {
+	buf.WriteByte('"')
+	for _, char := range str {
+		switch char {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if char < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, char)
+			} else {
+				buf.WriteRune(char)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// formats a JSON number as a double the way ECMAScript Number.prototype.toString does
+func formatJSONNumber(number json.Number) (string, error) {
+	value, err := strconv.ParseFloat(string(number), 64)
+	if err != nil || math.IsInf(value, 0) {
+		return "", fmt.Errorf("%w: number %s is out of range", ErrJSONCanonicalizationInvalid, number)
+	}
+
+	if value == 0 {
+		return "0", nil
+	}
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+
+	// the shortest representation, which is parsed back to the same double - "d.ddde±x"
+	scientific := strconv.FormatFloat(value, 'e', -1, 64)
+	mantissa, exponentStr, _ := strings.Cut(scientific, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	exponent, _ := strconv.Atoi(exponentStr)
+
+	// the value is 0.digits * 10^n
+	k := len(digits)
+	n := exponent + 1
+
+	var result string
+	switch {
+	case k <= n && n <= 21:
+		result = digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		result = digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		result = "0." + strings.Repeat("0", -n) + digits
+	default:
+		exponentSign := "+"
+		exponent = n - 1
+		if exponent < 0 {
+			exponentSign = "-"
+			exponent = -exponent
+		}
+		result = digits[:1]
+		if k > 1 {
+			result += "." + digits[1:]
+		}
+		result += "e" + exponentSign + strconv.Itoa(exponent)
+	}
+
+	return sign + result, nil
+}
@@ -0,0 +1,216 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "RFC 8785 example",
+			input: `{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001], "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "literals": [null, true, false]}`,
+			want:  `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			name:  "nested objects",
+			input: "{\n  \"b\": {\"d\": 1, \"c\": [ {} , [] ]},\n  \"a\": \"\"\n}",
+			want:  `{"a":"","b":{"c":[{},[]],"d":1}}`,
+		},
+		{
+			name:  "keys sorted by UTF-16 code units",
+			input: `{"😀": 1, "דּ": 2, "a": 3, "é": 4}`,
+			want:  "{\"a\":3,\"é\":4,\"\U0001f600\":1,\"דּ\":2}",
+		},
+		{
+			name:  "numbers",
+			input: `[-0, 1e-7, 0.000001, 1e21, 123456789012345678901, -1.5E+2, 100]`,
+			want:  `[0,1e-7,0.000001,1e+21,123456789012345680000,-150,100]`,
+		},
+		{
+			name:  "surrogate pair",
+			input: `["\ud83d\ude00", "\\ud800"]`,
+			want:  "[\"\U0001f600\",\"\\\\ud800\"]",
+		},
+		{
+			name:  "scalar",
+			input: ` "a\tb" `,
+			want:  `"a\tb"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CanonicalizeJSON([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("CanonicalizeJSON() error = %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("CanonicalizeJSON() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+
+	errorCases := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{name: "duplicate key", input: `{"a": 1, "a": 2}`, err: ErrJSONCanonicalizationDuplicateKey},
+		{name: "trailing data", input: `{} {}`, err: ErrJSONCanonicalizationInvalid},
+		{name: "unterminated", input: `[1, 2`, err: ErrJSONCanonicalizationInvalid},
+		{name: "empty", input: ``, err: ErrJSONCanonicalizationInvalid},
+		{name: "invalid UTF-8", input: "\"\xff\"", err: ErrJSONCanonicalizationInvalid},
+		{name: "number out of range", input: `1e400`, err: ErrJSONCanonicalizationInvalid},
+		{name: "lone high surrogate", input: `"\ud800"`, err: ErrJSONCanonicalizationInvalid},
+		{name: "lone low surrogate", input: `{"a": "\uDC00"}`, err: ErrJSONCanonicalizationInvalid},
+		{name: "reversed surrogate pair", input: `"\udc00\ud800"`, err: ErrJSONCanonicalizationInvalid},
+		{name: "high surrogate followed by a character", input: `"\ud800\u0041"`, err: ErrJSONCanonicalizationInvalid},
+	}
+
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := CanonicalizeJSON([]byte(tc.input)); !errors.Is(err, tc.err) {
+				t.Errorf("CanonicalizeJSON() error = %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestCanonicalRequestBody(t *testing.T) {
+	contentType := "application/json; charset=utf-8"
+	body := `{ "b": 2, "a": 1.0 }`
+	canonicalBody := `{"a":1,"b":2}`
+
+	fields := OptionalFields{RequestContentType: &contentType, RequestBody: &body, CanonicalizeRequestBody: true}
+	encoded, err := fields.Encode()
+	if err != nil {
+		t.Fatalf("OptionalFields.Encode() error = %v", err)
+	}
+
+	want, err := EncodeOptionalFields(nil, &contentType, &canonicalBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want[0] |= OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY
+	if !reflect.DeepEqual(encoded, want) {
+		t.Errorf("OptionalFields.Encode() = %v, want %v", encoded, want)
+	}
+
+	var decoded OptionalFields
+	if err := decoded.Decode(encoded); err != nil {
+		t.Fatalf("OptionalFields.Decode() error = %v", err)
+	}
+	wantDecoded := OptionalFields{RequestContentType: &contentType, RequestBody: &canonicalBody, CanonicalizeRequestBody: true}
+	if !reflect.DeepEqual(decoded, wantDecoded) {
+		t.Errorf("OptionalFields.Decode() = %+v, want %+v", decoded, wantDecoded)
+	}
+
+	// the legacy decoder can't report that the body was canonicalized
+	if _, _, _, err := DecodeOptionalFields(encoded); !errors.Is(err, ErrDecodingOptionalsUnsupportedFlags) {
+		t.Errorf("DecodeOptionalFields() error = %v, want %v", err, ErrDecodingOptionalsUnsupportedFlags)
+	}
+
+	// a differently formatted body matches after canonicalization
+	if err := VerifyRequestBody(encoded, `{"b":2,"a":1}`); err != nil {
+		t.Errorf("VerifyRequestBody() error = %v", err)
+	}
+	if err := VerifyRequestBody(encoded, `{"a":1,"b":3}`); !errors.Is(err, ErrRequestBodyMismatch) {
+		t.Errorf("VerifyRequestBody() error = %v, want %v", err, ErrRequestBodyMismatch)
+	}
+
+	t.Run("commitment", func(t *testing.T) {
+		fields := OptionalFields{RequestContentType: &contentType, RequestBody: &body, CanonicalizeRequestBody: true}
+		if err := fields.CommitRequestBody(); err != nil {
+			t.Fatalf("OptionalFields.CommitRequestBody() error = %v", err)
+		}
+		if *fields.RequestBodyCommitment != NewRequestBodyCommitment(canonicalBody) {
+			t.Fatalf("OptionalFields.CommitRequestBody() = %v, want the commitment of the canonical body", fields.RequestBodyCommitment)
+		}
+
+		encoded, err := fields.Encode()
+		if err != nil {
+			t.Fatalf("OptionalFields.Encode() error = %v", err)
+		}
+		wantFlags := byte(OPTIONAL_FIELDS_HEADER_HAS_CONTENT_TYPE | OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY | OPTIONAL_FIELDS_HEADER_HAS_BODY_COMMITMENT | OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY)
+		if encoded[0] != wantFlags {
+			t.Errorf("OptionalFields.Encode() flags = %d, want %d", encoded[0], wantFlags)
+		}
+		if err := VerifyRequestBody(encoded, body); err != nil {
+			t.Errorf("VerifyRequestBody() error = %v", err)
+		}
+	})
+
+	t.Run("encode with options", func(t *testing.T) {
+		options := &OptionalFieldsEncodingOptions{CanonicalizeRequestBody: true}
+		got, err := EncodeOptionalFieldsWithOptions(nil, &contentType, &body, options)
+		if err != nil {
+			t.Fatalf("EncodeOptionalFieldsWithOptions() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, encoded) {
+			t.Errorf("EncodeOptionalFieldsWithOptions() = %v, want %v", got, encoded)
+		}
+
+		plain, err := EncodeOptionalFields(nil, &contentType, &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := EncodeOptionalFieldsWithOptions(nil, &contentType, &body, nil); err != nil || !reflect.DeepEqual(got, plain) {
+			t.Errorf("EncodeOptionalFieldsWithOptions() = %v, %v, want %v", got, err, plain)
+		}
+
+		textContentType := "text/plain"
+		if _, err := EncodeOptionalFieldsWithOptions(nil, &textContentType, &body, options); !errors.Is(err, ErrOptionalFieldsCanonicalizationNotJSON) {
+			t.Errorf("EncodeOptionalFieldsWithOptions() error = %v, want %v", err, ErrOptionalFieldsCanonicalizationNotJSON)
+		}
+		if _, err := EncodeOptionalFieldsWithOptions(nil, &contentType, nil, options); !errors.Is(err, ErrOptionalFieldsCanonicalizationNotJSON) {
+			t.Errorf("EncodeOptionalFieldsWithOptions() error = %v, want %v", err, ErrOptionalFieldsCanonicalizationNotJSON)
+		}
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		invalid := `{"a": 1,}`
+		fields := OptionalFields{RequestContentType: &contentType, RequestBody: &invalid, CanonicalizeRequestBody: true}
+		if _, err := fields.Encode(); !errors.Is(err, ErrJSONCanonicalizationInvalid) {
+			t.Errorf("OptionalFields.Encode() error = %v, want %v", err, ErrJSONCanonicalizationInvalid)
+		}
+		if err := fields.CommitRequestBody(); !errors.Is(err, ErrJSONCanonicalizationInvalid) {
+			t.Errorf("OptionalFields.CommitRequestBody() error = %v, want %v", err, ErrJSONCanonicalizationInvalid)
+		}
+	})
+
+	t.Run("not JSON content type", func(t *testing.T) {
+		textContentType := "text/plain"
+		fields := OptionalFields{RequestContentType: &textContentType, RequestBody: &body, CanonicalizeRequestBody: true}
+		if _, err := fields.Encode(); !errors.Is(err, ErrOptionalFieldsCanonicalizationNotJSON) {
+			t.Errorf("OptionalFields.Encode() error = %v, want %v", err, ErrOptionalFieldsCanonicalizationNotJSON)
+		}
+	})
+
+	t.Run("invalid flag value", func(t *testing.T) {
+		_, err := EncodeOptionalFieldMap(OptionalFieldMap{
+			OPTIONAL_FIELD_REQUEST_CONTENT_TYPE:      contentType,
+			OPTIONAL_FIELD_REQUEST_BODY:              body,
+			OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY: "true",
+		})
+		if !errors.Is(err, ErrOptionalFieldInvalidValue) {
+			t.Errorf("EncodeOptionalFieldMap() error = %v, want %v", err, ErrOptionalFieldInvalidValue)
+		}
+	})
+
+	t.Run("flag on a non-canonical body", func(t *testing.T) {
+		encoded, err := EncodeOptionalFields(nil, &contentType, &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded[0] |= OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY
+		if _, err := DecodeOptionalFieldMap(encoded); !errors.Is(err, ErrDecodingOptionalsInvalidCanonicalization) {
+			t.Errorf("DecodeOptionalFieldMap() error = %v, want %v", err, ErrDecodingOptionalsInvalidCanonicalization)
+		}
+	})
+}
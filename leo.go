@@ -0,0 +1,283 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zkportal/aleo-oracle-encoding/positionRecorder"
+)
+
+var (
+	ErrLeoPathOutOfRange = errors.New("position is outside of the Leo struct")
+	ErrLeoPathInvalid    = errors.New("invalid Leo struct field path")
+
+	ErrLeoStructInvalidLength = errors.New("data must be aligned to blocks and fit into the Leo struct")
+	ErrLeoStructInvalidSyntax = errors.New("invalid Leo struct literal")
+	ErrLeoStructDuplicate     = errors.New("Leo struct literal has a duplicate field")
+	ErrLeoStructInvalidValue  = errors.New("Leo struct field is not a valid u128 value")
+)
+
+var (
+	leoInnerStructRegexp = regexp.MustCompile(`c(\d+)\s*:\s*{([^{}]*)}`)
+	leoFieldRegexp       = regexp.MustCompile(`^f(\d+)\s*:\s*(\d+)u128$`)
+)
+
+const (
+	LEO_STRUCT_FIELDS = 32 // number of u128 fields in one inner struct of the formatted oracle data
+	LEO_STRUCTS       = 32 // number of inner structs in the formatted oracle data
+
+	// maximum number of blocks that can be formatted as a Leo struct
+	LEO_MAX_BLOCKS = LEO_STRUCTS * LEO_STRUCT_FIELDS
+)
+
+// LeoFieldPath is a path to one u128 field of the formatted oracle data, e.g. "c1.f5" is the field f5 of the inner struct c1.
+// One field holds exactly one block.
+type LeoFieldPath struct {
+	Struct int
+	Field  int
+}
+
+// LeoFieldPathOfBlock returns the path of the field, which holds the block with the given index
+func LeoFieldPathOfBlock(block int) (LeoFieldPath, error) {
+	if block < 0 || block >= LEO_MAX_BLOCKS {
+		return LeoFieldPath{}, ErrLeoPathOutOfRange
+	}
+
+	return LeoFieldPath{
+		Struct: block / LEO_STRUCT_FIELDS,
+		Field:  block % LEO_STRUCT_FIELDS,
+	}, nil
+}
+
+// ParseLeoFieldPath parses a path formatted as "c{struct}.f{field}"
+func ParseLeoFieldPath(path string) (LeoFieldPath, error) {
+	structPart, fieldPart, found := strings.Cut(path, ".")
+	if !found || !strings.HasPrefix(structPart, "c") || !strings.HasPrefix(fieldPart, "f") {
+		return LeoFieldPath{}, ErrLeoPathInvalid
+	}
+
+	structIndex, err := strconv.Atoi(structPart[1:])
+	if err != nil {
+		return LeoFieldPath{}, ErrLeoPathInvalid
+	}
+
+	fieldIndex, err := strconv.Atoi(fieldPart[1:])
+	if err != nil {
+		return LeoFieldPath{}, ErrLeoPathInvalid
+	}
+
+	if structIndex < 0 || structIndex >= LEO_STRUCTS || fieldIndex < 0 || fieldIndex >= LEO_STRUCT_FIELDS {
+		return LeoFieldPath{}, ErrLeoPathOutOfRange
+	}
+
+	return LeoFieldPath{Struct: structIndex, Field: fieldIndex}, nil
+}
+
+// Block returns the index of the block held by the field
+func (p LeoFieldPath) Block() int {
+	return p.Struct*LEO_STRUCT_FIELDS + p.Field
+}
+
+func (p LeoFieldPath) String() string {
+	return fmt.Sprintf("c%d.f%d", p.Struct, p.Field)
+}
+
+// MarshalText formats the path as "c{struct}.f{field}", which is also used for the JSON representation
+func (p LeoFieldPath) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *LeoFieldPath) UnmarshalText(text []byte) error {
+	parsed, err := ParseLeoFieldPath(string(text))
+	if err != nil {
+		return err
+	}
+
+	*p = parsed
+	return nil
+}
+
+// LeoComponentPaths describes where a component is located in the formatted oracle data
+type LeoComponentPaths struct {
+	Component string                        `json:"component"`
+	Position  positionRecorder.PositionInfo `json:"position"`
+	// Paths of all fields holding the component in the order of the blocks
+	Paths []LeoFieldPath `json:"paths"`
+	// Set when the component starts in one inner struct and ends in another one
+	CrossesStructBoundary bool `json:"crossesStructBoundary"`
+}
+
+// PositionToLeoPaths converts a block position to an ordered list of paths of the fields holding the blocks.
+// The second return value is true if the blocks don't fit into one inner struct.
+func PositionToLeoPaths(info *positionRecorder.PositionInfo) ([]LeoFieldPath, bool, error) {
+	if info == nil || info.Len < 0 || info.Pos < 0 || info.Pos+info.Len > LEO_MAX_BLOCKS {
+		return nil, false, ErrLeoPathOutOfRange
+	}
+
+	paths := make([]LeoFieldPath, 0, info.Len)
+	for block := info.Pos; block < info.Pos+info.Len; block++ {
+		path, err := LeoFieldPathOfBlock(block)
+		if err != nil {
+			return nil, false, err
+		}
+		paths = append(paths, path)
+	}
+
+	crossesBoundary := len(paths) > 0 && paths[0].Struct != paths[len(paths)-1].Struct
+
+	return paths, crossesBoundary, nil
+}
+
+// LeoPaths converts positions of all components to Leo struct field paths. The components are returned in the canonical order.
+func (p *ProofPositionalInfo) LeoPaths() ([]LeoComponentPaths, error) {
+	components := p.Components()
+	result := make([]LeoComponentPaths, 0, len(components))
+
+	for _, component := range components {
+		paths, crossesBoundary, err := PositionToLeoPaths(component.Position)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, component.Name)
+		}
+
+		result = append(result, LeoComponentPaths{
+			Component:             component.Name,
+			Position:              *component.Position,
+			Paths:                 paths,
+			CrossesStructBoundary: crossesBoundary,
+		})
+	}
+
+	return result, nil
+}
+
+// BlockToU128 interprets a block as a little-endian unsigned 128-bit number, which is how the block is represented in the Leo struct
+func BlockToU128(buf []byte) *big.Int {
+	if len(buf) != TARGET_ALIGNMENT {
+		return nil
+	}
+
+	bigEndian := make([]byte, TARGET_ALIGNMENT)
+	for i, b := range buf {
+		bigEndian[TARGET_ALIGNMENT-1-i] = b
+	}
+
+	return new(big.Int).SetBytes(bigEndian)
+}
+
+// U128ToBlock converts an unsigned 128-bit number to a block of its little-endian bytes
+func U128ToBlock(number *big.Int) ([]byte, error) {
+	if number == nil || number.Sign() < 0 || number.BitLen() > TARGET_ALIGNMENT*8 {
+		return nil, ErrLeoStructInvalidValue
+	}
+
+	block := number.FillBytes(make([]byte, TARGET_ALIGNMENT))
+	for i, j := 0, len(block)-1; i < j; i, j = i+1, j-1 {
+		block[i], block[j] = block[j], block[i]
+	}
+
+	return block, nil
+}
+
+// FormatLeoStruct formats encoded data as a Leo struct literal of LEO_STRUCTS structs of LEO_STRUCT_FIELDS u128 fields.
+// The data must be aligned to TARGET_ALIGNMENT and take at most LEO_MAX_BLOCKS blocks, missing blocks are formatted as zeroes.
+func FormatLeoStruct(buf []byte) (string, error) {
+	if len(buf)%TARGET_ALIGNMENT != 0 || len(buf) > LEO_MAX_BLOCKS*TARGET_ALIGNMENT {
+		return "", ErrLeoStructInvalidLength
+	}
+
+	var builder strings.Builder
+	emptyBlock := make([]byte, TARGET_ALIGNMENT)
+
+	builder.WriteString("{\n")
+	for structIndex := 0; structIndex < LEO_STRUCTS; structIndex++ {
+		builder.WriteString(fmt.Sprintf("  c%d: {\n", structIndex))
+		for fieldIndex := 0; fieldIndex < LEO_STRUCT_FIELDS; fieldIndex++ {
+			path := LeoFieldPath{Struct: structIndex, Field: fieldIndex}
+
+			block := emptyBlock
+			if offset := path.Block() * TARGET_ALIGNMENT; offset < len(buf) {
+				block = buf[offset : offset+TARGET_ALIGNMENT]
+			}
+
+			separator := ","
+			if fieldIndex == LEO_STRUCT_FIELDS-1 {
+				separator = ""
+			}
+			builder.WriteString(fmt.Sprintf("    f%d: %su128%s\n", fieldIndex, BlockToU128(block).String(), separator))
+		}
+
+		separator := ","
+		if structIndex == LEO_STRUCTS-1 {
+			separator = ""
+		}
+		builder.WriteString(fmt.Sprintf("  }%s\n", separator))
+	}
+	builder.WriteString("}")
+
+	return builder.String(), nil
+}
+
+// ParseLeoStruct parses a Leo struct literal created with FormatLeoStruct or printed by Aleo tools and returns all LEO_MAX_BLOCKS blocks of data.
+// Missing fields are treated as zeroes.
+func ParseLeoStruct(literal string) ([]byte, error) {
+	result := make([]byte, LEO_MAX_BLOCKS*TARGET_ALIGNMENT)
+	seen := make(map[LeoFieldPath]bool)
+
+	trimmed := strings.TrimSpace(literal)
+	if !strings.HasPrefix(trimmed, "{") || !strings.HasSuffix(trimmed, "}") {
+		return nil, ErrLeoStructInvalidSyntax
+	}
+
+	// everything except for the inner structs must be separators
+	remainder := leoInnerStructRegexp.ReplaceAllString(trimmed[1:len(trimmed)-1], "")
+	if strings.Trim(remainder, ", \t\r\n") != "" {
+		return nil, ErrLeoStructInvalidSyntax
+	}
+
+	for _, innerStruct := range leoInnerStructRegexp.FindAllStringSubmatch(trimmed, -1) {
+		structIndex, err := strconv.Atoi(innerStruct[1])
+		if err != nil || structIndex >= LEO_STRUCTS {
+			return nil, fmt.Errorf("%w: c%s", ErrLeoPathOutOfRange, innerStruct[1])
+		}
+
+		for _, field := range strings.Split(innerStruct[2], ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+
+			match := leoFieldRegexp.FindStringSubmatch(field)
+			if match == nil {
+				return nil, fmt.Errorf("%w: %q", ErrLeoStructInvalidSyntax, field)
+			}
+
+			fieldIndex, err := strconv.Atoi(match[1])
+			if err != nil || fieldIndex >= LEO_STRUCT_FIELDS {
+				return nil, fmt.Errorf("%w: c%d.f%s", ErrLeoPathOutOfRange, structIndex, match[1])
+			}
+
+			path := LeoFieldPath{Struct: structIndex, Field: fieldIndex}
+			if seen[path] {
+				return nil, fmt.Errorf("%w: %s", ErrLeoStructDuplicate, path)
+			}
+			seen[path] = true
+
+			number, ok := new(big.Int).SetString(match[2], 10)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrLeoStructInvalidValue, path)
+			}
+			block, err := U128ToBlock(number)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", err, path)
+			}
+
+			copy(result[path.Block()*TARGET_ALIGNMENT:], block)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,298 @@
+package aleoOracleEncoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zkportal/aleo-oracle-encoding/positionRecorder"
+)
+
+func TestLeoFieldPathOfBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		block   int
+		want    string
+		wantErr bool
+	}{
+		{name: "first block", block: 0, want: "c0.f0"},
+		{name: "last field of first struct", block: 31, want: "c0.f31"},
+		{name: "first field of second struct", block: 32, want: "c1.f0"},
+		{name: "random block", block: 333, want: "c10.f13"},
+		{name: "last block", block: 1023, want: "c31.f31"},
+		{name: "negative block", block: -1, wantErr: true},
+		{name: "block out of range", block: 1024, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LeoFieldPathOfBlock(tt.block)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LeoFieldPathOfBlock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("LeoFieldPathOfBlock() = %v, want %v", got, tt.want)
+			}
+			if got.Block() != tt.block {
+				t.Errorf("LeoFieldPath.Block() = %d, want %d", got.Block(), tt.block)
+			}
+		})
+	}
+}
+
+func TestParseLeoFieldPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    LeoFieldPath
+		wantErr bool
+	}{
+		{name: "valid", path: "c3.f17", want: LeoFieldPath{Struct: 3, Field: 17}},
+		{name: "valid zero", path: "c0.f0", want: LeoFieldPath{}},
+		{name: "empty", path: "", wantErr: true},
+		{name: "no separator", path: "c3f17", wantErr: true},
+		{name: "no prefix", path: "3.17", wantErr: true},
+		{name: "swapped prefix", path: "f3.c17", wantErr: true},
+		{name: "not a number", path: "ca.f1", wantErr: true},
+		{name: "struct out of range", path: "c32.f1", wantErr: true},
+		{name: "field out of range", path: "c1.f32", wantErr: true},
+		{name: "trailing data", path: "c1.f2.f3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLeoFieldPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseLeoFieldPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseLeoFieldPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionToLeoPaths(t *testing.T) {
+	tests := []struct {
+		name              string
+		info              *positionRecorder.PositionInfo
+		want              []string
+		wantCrossBoundary bool
+		wantErr           bool
+	}{
+		{
+			name:    "nil",
+			info:    nil,
+			wantErr: true,
+		},
+		{
+			name: "empty component",
+			info: &positionRecorder.PositionInfo{Pos: 4, Len: 0},
+			want: []string{},
+		},
+		{
+			name: "one block",
+			info: &positionRecorder.PositionInfo{Pos: 2, Len: 1},
+			want: []string{"c0.f2"},
+		},
+		{
+			name: "several blocks in one struct",
+			info: &positionRecorder.PositionInfo{Pos: 29, Len: 3},
+			want: []string{"c0.f29", "c0.f30", "c0.f31"},
+		},
+		{
+			name:              "crosses struct boundary",
+			info:              &positionRecorder.PositionInfo{Pos: 30, Len: 4},
+			want:              []string{"c0.f30", "c0.f31", "c1.f0", "c1.f1"},
+			wantCrossBoundary: true,
+		},
+		{
+			name: "ends at the last block",
+			info: &positionRecorder.PositionInfo{Pos: 1022, Len: 2},
+			want: []string{"c31.f30", "c31.f31"},
+		},
+		{
+			name:    "doesn't fit",
+			info:    &positionRecorder.PositionInfo{Pos: 1022, Len: 3},
+			wantErr: true,
+		},
+		{
+			name:    "negative position",
+			info:    &positionRecorder.PositionInfo{Pos: -1, Len: 3},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, crossesBoundary, err := PositionToLeoPaths(tt.info)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PositionToLeoPaths() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			gotStrings := make([]string, 0, len(got))
+			for _, path := range got {
+				gotStrings = append(gotStrings, path.String())
+			}
+			if !reflect.DeepEqual(gotStrings, tt.want) {
+				t.Errorf("PositionToLeoPaths() = %v, want %v", gotStrings, tt.want)
+			}
+			if crossesBoundary != tt.wantCrossBoundary {
+				t.Errorf("PositionToLeoPaths() crosses boundary = %v, want %v", crossesBoundary, tt.wantCrossBoundary)
+			}
+		})
+	}
+}
+
+func TestProofPositionalInfo_LeoPaths(t *testing.T) {
+	info := &ProofPositionalInfo{
+		Data:            positionRecorder.PositionInfo{Pos: 2, Len: 1},
+		Timestamp:       positionRecorder.PositionInfo{Pos: 3, Len: 1},
+		StatusCode:      positionRecorder.PositionInfo{Pos: 4, Len: 1},
+		Method:          positionRecorder.PositionInfo{Pos: 5, Len: 1},
+		ResponseFormat:  positionRecorder.PositionInfo{Pos: 6, Len: 1},
+		Url:             positionRecorder.PositionInfo{Pos: 7, Len: 3},
+		Selector:        positionRecorder.PositionInfo{Pos: 10, Len: 2},
+		EncodingOptions: positionRecorder.PositionInfo{Pos: 12, Len: 1},
+		RequestHeaders:  positionRecorder.PositionInfo{Pos: 13, Len: 17},
+		OptionalFields:  positionRecorder.PositionInfo{Pos: 30, Len: 4},
+	}
+
+	got, err := info.LeoPaths()
+	if err != nil {
+		t.Fatalf("ProofPositionalInfo.LeoPaths() error = %v", err)
+	}
+
+	wantNames := []string{"data", "timestamp", "statusCode", "method", "responseFormat", "url", "selector", "encodingOptions", "requestHeaders", "optionalFields"}
+	if len(got) != len(wantNames) {
+		t.Fatalf("ProofPositionalInfo.LeoPaths() returned %d components, want %d", len(got), len(wantNames))
+	}
+	for i, component := range got {
+		if component.Component != wantNames[i] {
+			t.Errorf("ProofPositionalInfo.LeoPaths() component %d = %s, want %s", i, component.Component, wantNames[i])
+		}
+		if len(component.Paths) != component.Position.Len {
+			t.Errorf("ProofPositionalInfo.LeoPaths() %s has %d paths, want %d", component.Component, len(component.Paths), component.Position.Len)
+		}
+		wantCrossing := component.Component == COMPONENT_OPTIONAL_FIELDS
+		if component.CrossesStructBoundary != wantCrossing {
+			t.Errorf("ProofPositionalInfo.LeoPaths() %s crosses boundary = %v, want %v", component.Component, component.CrossesStructBoundary, wantCrossing)
+		}
+	}
+
+	encoded, err := json.Marshal(got[9])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `{"component":"optionalFields","position":{"Pos":30,"Len":4},"paths":["c0.f30","c0.f31","c1.f0","c1.f1"],"crossesStructBoundary":true}`
+	if string(encoded) != wantJSON {
+		t.Errorf("LeoComponentPaths JSON = %s, want %s", encoded, wantJSON)
+	}
+
+	var decoded LeoComponentPaths
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("LeoComponentPaths JSON roundtrip error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, got[9]) {
+		t.Errorf("LeoComponentPaths JSON roundtrip = %v, want %v", decoded, got[9])
+	}
+
+	info.OptionalFields.Len = 1000
+	_, err = info.LeoPaths()
+	if !errors.Is(err, ErrLeoPathOutOfRange) {
+		t.Errorf("ProofPositionalInfo.LeoPaths() error = %v, want %v", err, ErrLeoPathOutOfRange)
+	}
+}
+
+func TestFormatLeoStruct(t *testing.T) {
+	t.Run("invalid length", func(t *testing.T) {
+		if _, err := FormatLeoStruct(make([]byte, 15)); err != ErrLeoStructInvalidLength {
+			t.Errorf("FormatLeoStruct() error = %v, want %v", err, ErrLeoStructInvalidLength)
+		}
+		if _, err := FormatLeoStruct(make([]byte, (LEO_MAX_BLOCKS+1)*TARGET_ALIGNMENT)); err != ErrLeoStructInvalidLength {
+			t.Errorf("FormatLeoStruct() error = %v, want %v", err, ErrLeoStructInvalidLength)
+		}
+	})
+
+	t.Run("roundtrip", func(t *testing.T) {
+		buf := make([]byte, 40*TARGET_ALIGNMENT)
+		// block 0 is 1, block 1 is the maximum u128 value, block 33 is 256
+		buf[0] = 1
+		for i := TARGET_ALIGNMENT; i < 2*TARGET_ALIGNMENT; i++ {
+			buf[i] = 0xff
+		}
+		buf[33*TARGET_ALIGNMENT+1] = 1
+
+		formatted, err := FormatLeoStruct(buf)
+		if err != nil {
+			t.Fatalf("FormatLeoStruct() error = %v", err)
+		}
+
+		for _, want := range []string{"{\n  c0: {\n    f0: 1u128,\n    f1: 340282366920938463463374607431768211455u128,\n", "  c1: {\n    f0: 0u128,\n    f1: 256u128,\n", "    f31: 0u128\n  }\n}"} {
+			if !strings.Contains(formatted, want) {
+				t.Errorf("FormatLeoStruct() = %s, expected to contain %q", formatted, want)
+			}
+		}
+
+		parsed, err := ParseLeoStruct(formatted)
+		if err != nil {
+			t.Fatalf("ParseLeoStruct() error = %v", err)
+		}
+		if len(parsed) != LEO_MAX_BLOCKS*TARGET_ALIGNMENT {
+			t.Fatalf("ParseLeoStruct() returned %d bytes, want %d", len(parsed), LEO_MAX_BLOCKS*TARGET_ALIGNMENT)
+		}
+		if !bytes.Equal(parsed[:len(buf)], buf) || !bytes.Equal(parsed[len(buf):], make([]byte, len(parsed)-len(buf))) {
+			t.Error("ParseLeoStruct() doesn't match the formatted data")
+		}
+	})
+}
+
+func TestParseLeoStruct(t *testing.T) {
+	tests := []struct {
+		name    string
+		literal string
+		want    map[int]byte
+		wantErr error
+	}{
+		{name: "empty struct", literal: "{}", want: map[int]byte{}},
+		{name: "compact", literal: "{c0:{f0:1u128,f2:2u128},c31:{f31:3u128}}", want: map[int]byte{0: 1, 2: 2, 1023: 3}},
+		{name: "whitespace", literal: " {\n c1: { f1: 5u128 },\n}\n", want: map[int]byte{33: 5}},
+		{name: "not a struct", literal: "c0: { f0: 1u128 }", wantErr: ErrLeoStructInvalidSyntax},
+		{name: "garbage between structs", literal: "{ c0: { f0: 1u128 }, x, c1: { f0: 1u128 } }", wantErr: ErrLeoStructInvalidSyntax},
+		{name: "wrong type", literal: "{ c0: { f0: 1u64 } }", wantErr: ErrLeoStructInvalidSyntax},
+		{name: "negative", literal: "{ c0: { f0: -1u128 } }", wantErr: ErrLeoStructInvalidSyntax},
+		{name: "too big", literal: "{ c0: { f0: 340282366920938463463374607431768211456u128 } }", wantErr: ErrLeoStructInvalidValue},
+		{name: "struct out of range", literal: "{ c32: { f0: 1u128 } }", wantErr: ErrLeoPathOutOfRange},
+		{name: "field out of range", literal: "{ c0: { f32: 1u128 } }", wantErr: ErrLeoPathOutOfRange},
+		{name: "duplicate", literal: "{ c0: { f0: 1u128 }, c0: { f0: 1u128 } }", wantErr: ErrLeoStructDuplicate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLeoStruct(tt.literal)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseLeoStruct() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			want := make([]byte, LEO_MAX_BLOCKS*TARGET_ALIGNMENT)
+			for block, value := range tt.want {
+				want[block*TARGET_ALIGNMENT] = value
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("ParseLeoStruct() returned unexpected data")
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMethodCodeUnknown          = errors.New("unknown HTTP method code")
+	ErrMethodCompactInvalidLength = errors.New("compact HTTP method must be encoded as 8 bytes")
+	ErrMethodEmpty                = errors.New("HTTP method is empty")
+)
+
+const (
+	METHOD_CODE_GET     = 1 // value used for encoding HTTP method GET for Aleo
+	METHOD_CODE_POST    = 2 // value used for encoding HTTP method POST for Aleo
+	METHOD_CODE_PUT     = 3 // value used for encoding HTTP method PUT for Aleo
+	METHOD_CODE_DELETE  = 4 // value used for encoding HTTP method DELETE for Aleo
+	METHOD_CODE_PATCH   = 5 // value used for encoding HTTP method PATCH for Aleo
+	METHOD_CODE_HEAD    = 6 // value used for encoding HTTP method HEAD for Aleo
+	METHOD_CODE_OPTIONS = 7 // value used for encoding HTTP method OPTIONS for Aleo
+	METHOD_CODE_CONNECT = 8 // value used for encoding HTTP method CONNECT for Aleo
+	METHOD_CODE_TRACE   = 9 // value used for encoding HTTP method TRACE for Aleo
+
+	// the length of a compact method in the meta header, the code is encoded as uint64
+	METHOD_COMPACT_LENGTH = 8
+)
+
+var methodCodes = map[string]uint64{
+	"GET":     METHOD_CODE_GET,
+	"POST":    METHOD_CODE_POST,
+	"PUT":     METHOD_CODE_PUT,
+	"DELETE":  METHOD_CODE_DELETE,
+	"PATCH":   METHOD_CODE_PATCH,
+	"HEAD":    METHOD_CODE_HEAD,
+	"OPTIONS": METHOD_CODE_OPTIONS,
+	"CONNECT": METHOD_CODE_CONNECT,
+	"TRACE":   METHOD_CODE_TRACE,
+}
+
+// Returns the code of a standard HTTP method. Method names are case-sensitive, only the uppercase standard methods have codes.
+func MethodCode(method string) (uint64, bool) {
+	code, ok := methodCodes[method]
+	return code, ok
+}
+
+// returns the standard HTTP method with the given code
+func methodFromCode(code uint64) (string, bool) {
+	for method, methodCode := range methodCodes {
+		if methodCode == code {
+			return method, true
+		}
+	}
+	return "", false
+}
+
+// Encodes an HTTP method. A standard method is encoded as its code in 8 little-endian bytes and compact is true,
+// the meta header must then have META_HEADER_FLAG_COMPACT_METHOD set. Any other method is encoded as a string.
+// Both encodings are padded to a whole number of blocks when written, see WriteWithPadding.
+func EncodeMethod(method string) (encoded []byte, compact bool, err error) {
+	if method == "" {
+		return nil, false, ErrMethodEmpty
+	}
+
+	if code, ok := MethodCode(method); ok {
+		return NumberToBytes(code), true, nil
+	}
+
+	return []byte(method), false, nil
+}
+
+// Decodes an HTTP method encoded with EncodeMethod. The method length and whether the method is compact must be taken from the meta header,
+// see MetaHeader.HasCompactMethod.
+func DecodeMethod(buf []byte, methodLen int, compact bool) (string, error) {
+	if methodLen < 0 || methodLen > len(buf) {
+		return "", ErrDecodingBufferTooShort
+	}
+
+	if !compact {
+		return string(buf[:methodLen]), nil
+	}
+
+	if methodLen != METHOD_COMPACT_LENGTH {
+		return "", fmt.Errorf("%w: %d", ErrMethodCompactInvalidLength, methodLen)
+	}
+
+	code := BytesToNumber(buf[:METHOD_COMPACT_LENGTH])
+	method, ok := methodFromCode(code)
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrMethodCodeUnknown, code)
+	}
+
+	return method, nil
+}
@@ -0,0 +1,118 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMethod(t *testing.T) {
+	for method, code := range methodCodes {
+		encoded, compact, err := EncodeMethod(method)
+		if err != nil || !compact {
+			t.Fatalf("EncodeMethod(%s) = %v, %v, want a compact method", method, compact, err)
+		}
+		if !reflect.DeepEqual(encoded, NumberToBytes(code)) {
+			t.Errorf("EncodeMethod(%s) = %v, want %v", method, encoded, NumberToBytes(code))
+		}
+
+		// decoded from a padded block
+		block := append(encoded, make([]byte, TARGET_ALIGNMENT/2)...)
+		decoded, err := DecodeMethod(block, len(encoded), true)
+		if err != nil || decoded != method {
+			t.Errorf("DecodeMethod() = %s, %v, want %s", decoded, err, method)
+		}
+	}
+
+	// custom verbs and methods in a different case are encoded as strings
+	for _, method := range []string{"PROPFIND", "get", "Post"} {
+		encoded, compact, err := EncodeMethod(method)
+		if err != nil || compact || string(encoded) != method {
+			t.Errorf("EncodeMethod(%s) = %s, %v, %v, want a string", method, encoded, compact, err)
+		}
+
+		decoded, err := DecodeMethod(append(encoded, getPadding(encoded, TARGET_ALIGNMENT)...), len(encoded), false)
+		if err != nil || decoded != method {
+			t.Errorf("DecodeMethod() = %s, %v, want %s", decoded, err, method)
+		}
+	}
+
+	if _, _, err := EncodeMethod(""); !errors.Is(err, ErrMethodEmpty) {
+		t.Errorf("EncodeMethod() error = %v, want %v", err, ErrMethodEmpty)
+	}
+
+	decodeErrors := []struct {
+		name      string
+		buf       []byte
+		methodLen int
+		compact   bool
+		wantErr   error
+	}{
+		{name: "nil", buf: nil, methodLen: METHOD_COMPACT_LENGTH, compact: true, wantErr: ErrDecodingBufferTooShort},
+		{name: "string longer than buffer", buf: make([]byte, TARGET_ALIGNMENT), methodLen: TARGET_ALIGNMENT + 1, wantErr: ErrDecodingBufferTooShort},
+		{name: "negative length", buf: make([]byte, TARGET_ALIGNMENT), methodLen: -1, wantErr: ErrDecodingBufferTooShort},
+		{name: "compact with string length", buf: []byte("GET" + strings.Repeat("\x00", 13)), methodLen: 3, compact: true, wantErr: ErrMethodCompactInvalidLength},
+		{name: "zero code", buf: make([]byte, TARGET_ALIGNMENT), methodLen: METHOD_COMPACT_LENGTH, compact: true, wantErr: ErrMethodCodeUnknown},
+		{name: "unknown code", buf: NumberToBytes(METHOD_CODE_TRACE + 1), methodLen: METHOD_COMPACT_LENGTH, compact: true, wantErr: ErrMethodCodeUnknown},
+	}
+	for _, tt := range decodeErrors {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeMethod(tt.buf, tt.methodLen, tt.compact); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeMethod() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetMetaHeaderFlags(t *testing.T) {
+	header := make([]byte, TARGET_ALIGNMENT*2)
+	if err := CreateMetaHeader(header, 4, METHOD_COMPACT_LENGTH, 16, 3, 32, 48); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetMetaHeaderFlags(header, META_HEADER_FLAG_COMPACT_METHOD); err != nil {
+		t.Fatalf("SetMetaHeaderFlags() error = %v", err)
+	}
+
+	decoded, err := DecodeMetaHeader(header)
+	if err != nil {
+		t.Fatalf("DecodeMetaHeader() error = %v", err)
+	}
+	if !decoded.HasCompactMethod() || decoded.MethodLen != METHOD_COMPACT_LENGTH {
+		t.Errorf("DecodeMetaHeader() = %+v, want a compact method", decoded)
+	}
+
+	if err := SetMetaHeaderFlags(header, 2); !errors.Is(err, ErrEncodingMetaHeaderUnknownFlags) {
+		t.Errorf("SetMetaHeaderFlags() error = %v, want %v", err, ErrEncodingMetaHeaderUnknownFlags)
+	}
+	if err := SetMetaHeaderFlags(header[:TARGET_ALIGNMENT], META_HEADER_FLAG_COMPACT_METHOD); !errors.Is(err, ErrEncodingMetaHeaderInvalidSize) {
+		t.Errorf("SetMetaHeaderFlags() error = %v, want %v", err, ErrEncodingMetaHeaderInvalidSize)
+	}
+}
+
+func TestAnnotateCompactMethod(t *testing.T) {
+	report := &testReport{
+		data:            "abc",
+		encodingOptions: EncodingOptions{Value: ENCODING_OPTION_STRING},
+		timestamp:       1700000000,
+		statusCode:      200,
+		method:          "DELETE",
+		responseFormat:  RESPONSE_FORMAT_JSON,
+		url:             "example.com",
+		selector:        "a",
+		compactMethod:   true,
+	}
+	encoded, positions := encodeTestReport(t, report)
+
+	blocks, err := AnnotateBlocks(encoded)
+	if err != nil {
+		t.Fatalf("AnnotateBlocks() error = %v", err)
+	}
+
+	block := blocks[positions.Method.Pos]
+	var want [TARGET_ALIGNMENT]ByteKind
+	copy(want[:], classifyPrefix(TARGET_ALIGNMENT, METHOD_COMPACT_LENGTH, BYTE_KIND_RESERVED))
+	if block.Component != COMPONENT_METHOD || block.Kinds != want {
+		t.Errorf("AnnotateBlocks() method block = %+v, want %v", block, want)
+	}
+}
@@ -0,0 +1,695 @@
+package aleoOracleEncoding
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrOptionalFieldUnknown           = errors.New("unknown optional field")
+	ErrOptionalFieldInvalidValue      = errors.New("optional field value doesn't match the field type")
+	ErrOptionalFieldInvalidTag        = errors.New("optional field tag must not be 0")
+	ErrOptionalFieldInvalidType       = errors.New("unknown optional field type")
+	ErrOptionalFieldAlreadyRegistered = errors.New("optional field with the same name or tag is already registered")
+
+	ErrDecodingOptionalsUnknownFlags       = errors.New("optional fields header contains unknown flags")
+	ErrDecodingOptionalsInvalidExtension   = errors.New("invalid encoding of an extension optional field")
+	ErrDecodingOptionalsUnorderedExtension = errors.New("extension optional fields are not sorted by tag")
+
+	ErrOptionalFieldsPostOnly = errors.New("optional field can be used only with POST request method")
+	ErrOptionalFieldsHtmlOnly = errors.New("HTML result type can be used only with HTML response format")
+)
+
+const (
+	OPTIONAL_FIELDS_HEADER_HAS_EXTENSIONS = 8 // bit flag used for encoding presence of extension fields for Aleo
+
+	// all bit flags of the optional fields header, which are known to this version of the encoding
+	optionalFieldsHeaderKnownFlags = OPTIONAL_FIELDS_HEADER_HAS_HTML_RESULT_TYPE | OPTIONAL_FIELDS_HEADER_HAS_CONTENT_TYPE |
+		OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY | OPTIONAL_FIELDS_HEADER_HAS_EXTENSIONS | OPTIONAL_FIELDS_HEADER_HAS_BODY_COMMITMENT |
+		OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY
+)
+
+// Names of the optional fields. The first three fields are encoded in the fixed layout of EncodeOptionalFields, the rest are extension fields.
+const (
+	OPTIONAL_FIELD_HTML_RESULT_TYPE      = "htmlResultType"
+	OPTIONAL_FIELD_REQUEST_CONTENT_TYPE  = "requestContentType"
+	OPTIONAL_FIELD_REQUEST_BODY          = "requestBody"
+	OPTIONAL_FIELD_QUERY_PARAMETERS      = "queryParameters"     // URL-encoded query string, string
+	OPTIONAL_FIELD_FOLLOW_REDIRECTS      = "followRedirects"     // redirect-follow policy, bool
+	OPTIONAL_FIELD_TIMEOUT               = "timeout"             // request timeout in milliseconds, uint64
+	OPTIONAL_FIELD_TLS_SERVER_NAME       = "tlsServerName"       // TLS server name indication, string
+	OPTIONAL_FIELD_RESPONSE_CONTENT_TYPE = "responseContentType" // content type of the response, string
+	OPTIONAL_FIELD_XML_RESULT_TYPE       = "xmlResultType"       // XPath result type of the selector for XML responses, string
+	OPTIONAL_FIELD_CSV_ROW               = "csvRow"              // zero-based index of the selected row for CSV responses, uint64
+	OPTIONAL_FIELD_CSV_COLUMN            = "csvColumn"           // zero-based index of the selected column for CSV responses, uint64
+	OPTIONAL_FIELD_CSV_HAS_HEADER        = "csvHasHeader"        // whether the first row of CSV responses is a header, which is not counted by csvRow, bool
+	OPTIONAL_FIELD_GRAPHQL_OPERATION     = "graphqlOperation"    // name of the executed operation for GraphQL responses, string
+)
+
+// Tags of the built-in extension fields
+const (
+	OPTIONAL_FIELD_TAG_QUERY_PARAMETERS      = 1
+	OPTIONAL_FIELD_TAG_FOLLOW_REDIRECTS      = 2
+	OPTIONAL_FIELD_TAG_TIMEOUT               = 3
+	OPTIONAL_FIELD_TAG_TLS_SERVER_NAME       = 4
+	OPTIONAL_FIELD_TAG_RESPONSE_CONTENT_TYPE = 5
+	OPTIONAL_FIELD_TAG_XML_RESULT_TYPE       = 6
+	OPTIONAL_FIELD_TAG_CSV_ROW               = 7
+	OPTIONAL_FIELD_TAG_CSV_COLUMN            = 8
+	OPTIONAL_FIELD_TAG_CSV_HAS_HEADER        = 9
+	OPTIONAL_FIELD_TAG_GRAPHQL_OPERATION     = 10
+)
+
+// OptionalFieldType is the type of an extension optional field value
+type OptionalFieldType int
+
+const (
+	OPTIONAL_FIELD_TYPE_STRING OptionalFieldType = iota // string, encoded as character codes
+	OPTIONAL_FIELD_TYPE_UINT64                          // uint64, encoded as 8 little-endian bytes
+	OPTIONAL_FIELD_TYPE_BOOL                            // bool, encoded as 1 byte - 0 or 1
+)
+
+// OptionalFieldDefinition describes an extension optional field
+type OptionalFieldDefinition struct {
+	Tag  uint64
+	Name string
+	Type OptionalFieldType
+}
+
+// OptionalFieldMap contains optional fields by name. The values of the fixed fields are strings, except the request body commitment, which is
+// RequestBodyCommitment, and the request body canonicalization flag, which is bool. The values of the extension fields are string, uint64 or bool depending on the type of the field.
+type OptionalFieldMap map[string]any
+
+// OptionalFieldRegistry contains definitions of extension optional fields. A registry created with NewOptionalFieldRegistry contains the built-in fields,
+// more fields can be added with Register. The package-level EncodeOptionalFieldMap and DecodeOptionalFieldMap use a registry of the built-in fields,
+// which cannot be changed, so decoding doesn't depend on fields registered by other packages.
+type OptionalFieldRegistry struct {
+	mu     sync.RWMutex
+	byTag  map[uint64]OptionalFieldDefinition
+	byName map[string]OptionalFieldDefinition
+}
+
+// the registry used by the package-level functions, must not be modified after init
+var builtInOptionalFields = NewOptionalFieldRegistry()
+
+// NewOptionalFieldRegistry returns a registry with the built-in extension optional fields
+func NewOptionalFieldRegistry() *OptionalFieldRegistry {
+	registry := &OptionalFieldRegistry{
+		byTag:  make(map[uint64]OptionalFieldDefinition),
+		byName: make(map[string]OptionalFieldDefinition),
+	}
+
+	builtIn := []OptionalFieldDefinition{
+		{Tag: OPTIONAL_FIELD_TAG_QUERY_PARAMETERS, Name: OPTIONAL_FIELD_QUERY_PARAMETERS, Type: OPTIONAL_FIELD_TYPE_STRING},
+		{Tag: OPTIONAL_FIELD_TAG_FOLLOW_REDIRECTS, Name: OPTIONAL_FIELD_FOLLOW_REDIRECTS, Type: OPTIONAL_FIELD_TYPE_BOOL},
+		{Tag: OPTIONAL_FIELD_TAG_TIMEOUT, Name: OPTIONAL_FIELD_TIMEOUT, Type: OPTIONAL_FIELD_TYPE_UINT64},
+		{Tag: OPTIONAL_FIELD_TAG_TLS_SERVER_NAME, Name: OPTIONAL_FIELD_TLS_SERVER_NAME, Type: OPTIONAL_FIELD_TYPE_STRING},
+		{Tag: OPTIONAL_FIELD_TAG_RESPONSE_CONTENT_TYPE, Name: OPTIONAL_FIELD_RESPONSE_CONTENT_TYPE, Type: OPTIONAL_FIELD_TYPE_STRING},
+		{Tag: OPTIONAL_FIELD_TAG_XML_RESULT_TYPE, Name: OPTIONAL_FIELD_XML_RESULT_TYPE, Type: OPTIONAL_FIELD_TYPE_STRING},
+		{Tag: OPTIONAL_FIELD_TAG_CSV_ROW, Name: OPTIONAL_FIELD_CSV_ROW, Type: OPTIONAL_FIELD_TYPE_UINT64},
+		{Tag: OPTIONAL_FIELD_TAG_CSV_COLUMN, Name: OPTIONAL_FIELD_CSV_COLUMN, Type: OPTIONAL_FIELD_TYPE_UINT64},
+		{Tag: OPTIONAL_FIELD_TAG_CSV_HAS_HEADER, Name: OPTIONAL_FIELD_CSV_HAS_HEADER, Type: OPTIONAL_FIELD_TYPE_BOOL},
+		{Tag: OPTIONAL_FIELD_TAG_GRAPHQL_OPERATION, Name: OPTIONAL_FIELD_GRAPHQL_OPERATION, Type: OPTIONAL_FIELD_TYPE_STRING},
+	}
+	for _, definition := range builtIn {
+		if err := registry.Register(definition); err != nil {
+			panic(err)
+		}
+	}
+
+	return registry
+}
+
+func isFixedOptionalField(name string) bool {
+	switch name {
+	case OPTIONAL_FIELD_HTML_RESULT_TYPE, OPTIONAL_FIELD_REQUEST_CONTENT_TYPE, OPTIONAL_FIELD_REQUEST_BODY, OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT,
+		OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY:
+		return true
+	default:
+		return false
+	}
+}
+
+// Register adds an extension optional field to the registry, so it can be encoded and decoded with the registry. The tag and the name must be unique,
+// the names of the fixed fields cannot be used.
+func (r *OptionalFieldRegistry) Register(definition OptionalFieldDefinition) error {
+	if definition.Tag == 0 {
+		return ErrOptionalFieldInvalidTag
+	}
+	if definition.Type < OPTIONAL_FIELD_TYPE_STRING || definition.Type > OPTIONAL_FIELD_TYPE_BOOL {
+		return ErrOptionalFieldInvalidType
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, tagTaken := r.byTag[definition.Tag]
+	_, nameTaken := r.byName[definition.Name]
+	if tagTaken || nameTaken || isFixedOptionalField(definition.Name) {
+		return fmt.Errorf("%w: %s", ErrOptionalFieldAlreadyRegistered, definition.Name)
+	}
+
+	r.byTag[definition.Tag] = definition
+	r.byName[definition.Name] = definition
+
+	return nil
+}
+
+// Lookup returns the definition of a registered extension optional field by name
+func (r *OptionalFieldRegistry) Lookup(name string) (OptionalFieldDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definition, ok := r.byName[name]
+	return definition, ok
+}
+
+// LookupTag returns the definition of a registered extension optional field by tag
+func (r *OptionalFieldRegistry) LookupTag(tag uint64) (OptionalFieldDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definition, ok := r.byTag[tag]
+	return definition, ok
+}
+
+// LookupOptionalField returns the definition of a built-in extension optional field by name
+func LookupOptionalField(name string) (OptionalFieldDefinition, bool) {
+	return builtInOptionalFields.Lookup(name)
+}
+
+// LookupOptionalFieldTag returns the definition of a built-in extension optional field by tag
+func LookupOptionalFieldTag(tag uint64) (OptionalFieldDefinition, bool) {
+	return builtInOptionalFields.LookupTag(tag)
+}
+
+func encodeOptionalFieldValue(definition OptionalFieldDefinition, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		if definition.Type == OPTIONAL_FIELD_TYPE_STRING {
+			return []byte(v), nil
+		}
+	case uint64:
+		if definition.Type == OPTIONAL_FIELD_TYPE_UINT64 {
+			return NumberToBytes(v), nil
+		}
+	case bool:
+		if definition.Type == OPTIONAL_FIELD_TYPE_BOOL {
+			if v {
+				return []byte{1}, nil
+			}
+			return []byte{0}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrOptionalFieldInvalidValue, definition.Name)
+}
+
+func decodeOptionalFieldValue(definition OptionalFieldDefinition, value []byte) (any, error) {
+	switch definition.Type {
+	case OPTIONAL_FIELD_TYPE_STRING:
+		return string(value), nil
+	case OPTIONAL_FIELD_TYPE_UINT64:
+		if len(value) == TARGET_ALIGNMENT/2 {
+			return BytesToNumber(value), nil
+		}
+	case OPTIONAL_FIELD_TYPE_BOOL:
+		if len(value) == 1 && value[0] <= 1 {
+			return value[0] == 1, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrDecodingOptionalsInvalidExtension, definition.Name)
+}
+
+// returns the fixed field as a string pointer, nil if the field is not in the map
+func fixedOptionalField(fields OptionalFieldMap, name string) (*string, error) {
+	value, ok := fields[name]
+	if !ok {
+		return nil, nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOptionalFieldInvalidValue, name)
+	}
+	return &str, nil
+}
+
+// Encodes optional fields from a map. The fixed fields - OPTIONAL_FIELD_HTML_RESULT_TYPE, OPTIONAL_FIELD_REQUEST_CONTENT_TYPE and OPTIONAL_FIELD_REQUEST_BODY
+// are encoded using EncodeOptionalFields. If there are other fields, they must be built-in extension fields, and they are appended
+// as an extension section, which is marked with OPTIONAL_FIELDS_HEADER_HAS_EXTENSIONS bit in the header. Without extension fields
+// the result is the same as the result of EncodeOptionalFields.
+//
+// The extension section starts with 1 block, where the first 8 little-endian bytes encode the number of the fields. It's followed by the fields
+// sorted by tag, every field is encoded as 1 block with the tag in the first 8 bytes and the length of the value in bytes in the last 8 bytes,
+// followed by the value padded to TARGET_ALIGNMENT.
+//
+// If the map contains OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT with a RequestBodyCommitment value, the request body is encoded as the commitment -
+// 1 block with the body length in the first 8 bytes followed by 2 blocks of the SHA-256 digest, and OPTIONAL_FIELDS_HEADER_HAS_BODY_COMMITMENT bit is set
+// together with OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY. The map cannot contain both the request body and its commitment.
+//
+// If OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY is true, the request body is canonicalized with CanonicalizeJSON before encoding and
+// OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY bit is set. The request content type must be JSON, and there must be a request body or
+// a commitment of a canonicalized body.
+//
+// Use OptionalFieldRegistry.EncodeOptionalFieldMap to encode extension fields, which are not built-in.
+func EncodeOptionalFieldMap(fields OptionalFieldMap) ([]byte, error) {
+	return builtInOptionalFields.EncodeOptionalFieldMap(fields)
+}
+
+// EncodeOptionalFieldMap works like the package-level EncodeOptionalFieldMap, but the extension fields must be registered in r.
+func (r *OptionalFieldRegistry) EncodeOptionalFieldMap(fields OptionalFieldMap) ([]byte, error) {
+	var fixed [3]*string
+	for i, name := range []string{OPTIONAL_FIELD_HTML_RESULT_TYPE, OPTIONAL_FIELD_REQUEST_CONTENT_TYPE, OPTIONAL_FIELD_REQUEST_BODY} {
+		value, err := fixedOptionalField(fields, name)
+		if err != nil {
+			return nil, err
+		}
+		fixed[i] = value
+	}
+
+	canonicalize, err := requestBodyCanonicalizationFlag(fields)
+	if err != nil {
+		return nil, err
+	}
+	// the body is canonicalized by EncodeOptionalFieldsWithOptions, the commitment must be computed from a canonicalized body
+	options := &OptionalFieldsEncodingOptions{CanonicalizeRequestBody: canonicalize && fixed[2] != nil}
+	if canonicalize && fixed[2] == nil {
+		_, hasCommitment := fields[OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT]
+		if fixed[1] == nil || !isJSONContentType(*fixed[1]) || !hasCommitment {
+			return nil, ErrOptionalFieldsCanonicalizationNotJSON
+		}
+	}
+
+	buf, err := EncodeOptionalFieldsWithOptions(fixed[0], fixed[1], fixed[2], options)
+	if err != nil {
+		return nil, err
+	}
+
+	if canonicalize {
+		buf[0] |= OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY
+	}
+
+	if value, ok := fields[OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT]; ok {
+		commitment, ok := value.(RequestBodyCommitment)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrOptionalFieldInvalidValue, OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT)
+		}
+		if fixed[2] != nil {
+			return nil, ErrOptionalFieldsBodyAndCommitment
+		}
+
+		// replace the empty request body block, which is the last block of the fixed fields
+		buf = append(buf[:len(buf)-TARGET_ALIGNMENT], encodeBodyCommitment(commitment)...)
+		buf[0] |= OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY | OPTIONAL_FIELDS_HEADER_HAS_BODY_COMMITMENT
+	}
+
+	var extensions []OptionalFieldDefinition
+	for name := range fields {
+		if isFixedOptionalField(name) {
+			continue
+		}
+
+		definition, ok := r.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrOptionalFieldUnknown, name)
+		}
+		extensions = append(extensions, definition)
+	}
+
+	if len(extensions) != 0 {
+		buf, err = appendOptionalFieldExtensions(buf, extensions, fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	copy(buf[TARGET_ALIGNMENT/2:TARGET_ALIGNMENT], NumberToBytes(uint64(len(buf)/TARGET_ALIGNMENT-1)))
+
+	return buf, nil
+}
+
+// appends the extension section with the given fields to the encoded optional fields and sets the flag in the header
+func appendOptionalFieldExtensions(buf []byte, extensions []OptionalFieldDefinition, fields OptionalFieldMap) ([]byte, error) {
+	sort.Slice(extensions, func(i, j int) bool {
+		return extensions[i].Tag < extensions[j].Tag
+	})
+
+	sectionHeader := make([]byte, TARGET_ALIGNMENT)
+	copy(sectionHeader, NumberToBytes(uint64(len(extensions))))
+	buf = append(buf, sectionHeader...)
+
+	for _, definition := range extensions {
+		value, err := encodeOptionalFieldValue(definition, fields[definition.Name])
+		if err != nil {
+			return nil, err
+		}
+
+		buf = append(buf, NumberToBytes(definition.Tag)...)
+		buf = append(buf, NumberToBytes(uint64(len(value)))...)
+		buf = append(buf, value...)
+		buf = append(buf, getPadding(value, TARGET_ALIGNMENT)...)
+	}
+
+	buf[0] |= OPTIONAL_FIELDS_HEADER_HAS_EXTENSIONS
+
+	return buf, nil
+}
+
+// reads data of the given length starting at offset and padded to TARGET_ALIGNMENT, returns the data and the offset after the padding
+func readPadded(buf []byte, offset int, length uint64) ([]byte, int, error) {
+	if offset > len(buf) || length > uint64(len(buf)-offset) {
+		return nil, 0, ErrDecodingBufferTooShort
+	}
+
+	end := offset + int(length)
+	paddedEnd := offset + blocksForLength(int(length))*TARGET_ALIGNMENT
+	if paddedEnd > len(buf) {
+		return nil, 0, ErrDecodingBufferTooShort
+	}
+	if !bytes.Equal(buf[end:paddedEnd], make([]byte, paddedEnd-end)) {
+		return nil, 0, ErrDecodingUnexpectedPadding
+	}
+
+	return buf[offset:end], paddedEnd, nil
+}
+
+// reads a block with a length in the first 8 bytes followed by the data of this length padded to TARGET_ALIGNMENT. Returns the data and the offset after the padding.
+func readLengthPrefixed(buf []byte, offset int) ([]byte, int, error) {
+	if offset+TARGET_ALIGNMENT > len(buf) {
+		return nil, 0, ErrDecodingBufferTooShort
+	}
+
+	length := BytesToNumber(buf[offset : offset+TARGET_ALIGNMENT/2])
+	return readPadded(buf, offset+TARGET_ALIGNMENT, length)
+}
+
+// decodes the extension section of the optional fields starting at offset into fields, returns the offset after the section
+func (r *OptionalFieldRegistry) decodeOptionalFieldExtensions(buf []byte, offset int, fields OptionalFieldMap) (int, error) {
+	if offset+TARGET_ALIGNMENT > len(buf) {
+		return 0, ErrDecodingBufferTooShort
+	}
+
+	count := BytesToNumber(buf[offset : offset+TARGET_ALIGNMENT/2])
+	offset += TARGET_ALIGNMENT
+
+	// every field takes at least 1 block
+	if count > uint64((len(buf)-offset)/TARGET_ALIGNMENT) {
+		return 0, ErrDecodingOptionalsInvalidExtension
+	}
+
+	var previousTag uint64
+	for i := uint64(0); i < count; i++ {
+		// the values of the previous fields can take more than 1 block, so the number of fields doesn't guarantee that the field block is in the buffer
+		if offset+TARGET_ALIGNMENT > len(buf) {
+			return 0, ErrDecodingOptionalsInvalidExtension
+		}
+
+		tag := BytesToNumber(buf[offset : offset+TARGET_ALIGNMENT/2])
+		length := BytesToNumber(buf[offset+TARGET_ALIGNMENT/2 : offset+TARGET_ALIGNMENT])
+		if tag <= previousTag {
+			return 0, ErrDecodingOptionalsUnorderedExtension
+		}
+		previousTag = tag
+
+		definition, ok := r.LookupTag(tag)
+		if !ok {
+			return 0, fmt.Errorf("%w: tag %d", ErrOptionalFieldUnknown, tag)
+		}
+
+		var value []byte
+		var err error
+		value, offset, err = readPadded(buf, offset+TARGET_ALIGNMENT, length)
+		if err != nil {
+			return 0, err
+		}
+
+		fields[definition.Name], err = decodeOptionalFieldValue(definition, value)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return offset, nil
+}
+
+// Decodes optional fields created with EncodeOptionalFields or EncodeOptionalFieldMap to a map of typed fields. The map contains only the fields, which are present.
+// This is the typed counterpart of DecodeOptionalFields, which keeps its signature of 3 string pointers for the existing callers and decodes only
+// the 3 fixed fields.
+//
+// Only the built-in extension fields are decoded, an unknown tag is rejected with ErrOptionalFieldUnknown. Use OptionalFieldRegistry.DecodeOptionalFieldMap
+// to decode other extension fields.
+func DecodeOptionalFieldMap(buf []byte) (OptionalFieldMap, error) {
+	return builtInOptionalFields.DecodeOptionalFieldMap(buf)
+}
+
+// DecodeOptionalFieldMap works like the package-level DecodeOptionalFieldMap, but decodes the extension fields registered in r.
+func (r *OptionalFieldRegistry) DecodeOptionalFieldMap(buf []byte) (OptionalFieldMap, error) {
+	if len(buf) < 4*TARGET_ALIGNMENT || len(buf)%TARGET_ALIGNMENT != 0 {
+		return nil, ErrDecodingBufferTooShort
+	}
+
+	header := buf[0:TARGET_ALIGNMENT]
+	blockCount := BytesToNumber(header[TARGET_ALIGNMENT/2 : TARGET_ALIGNMENT])
+
+	// check that the header encodes the correct number of of the following blocks
+	if blockCount != uint64(len(buf)/TARGET_ALIGNMENT-1) {
+		return nil, ErrDecodingOptionalsCountLengthMismatch
+	}
+
+	flags := header[0]
+	if flags&^optionalFieldsHeaderKnownFlags != 0 {
+		return nil, ErrDecodingOptionalsUnknownFlags
+	}
+
+	fields := make(OptionalFieldMap)
+	offset := TARGET_ALIGNMENT
+
+	if flags&OPTIONAL_FIELDS_HEADER_HAS_HTML_RESULT_TYPE != 0 {
+		resultType, next, err := decodeHtmlResultType(buf, offset)
+		if err != nil {
+			return nil, err
+		}
+		fields[OPTIONAL_FIELD_HTML_RESULT_TYPE] = resultType
+		offset = next
+	} else {
+		offset += TARGET_ALIGNMENT
+	}
+
+	stringFields := []struct {
+		name string
+		flag byte
+		err  error
+	}{
+		{name: OPTIONAL_FIELD_REQUEST_CONTENT_TYPE, flag: OPTIONAL_FIELDS_HEADER_HAS_CONTENT_TYPE, err: ErrDecodingOptionalsInvalidContentTypeLength},
+		{name: OPTIONAL_FIELD_REQUEST_BODY, flag: OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY, err: ErrDecodingOptionalsInvalidBodyLength},
+	}
+	for _, field := range stringFields {
+		if field.name == OPTIONAL_FIELD_REQUEST_BODY && flags&OPTIONAL_FIELDS_HEADER_HAS_BODY_COMMITMENT != 0 {
+			if flags&OPTIONAL_FIELDS_HEADER_HAS_REQUEST_BODY == 0 {
+				return nil, ErrDecodingOptionalsInvalidBodyCommitment
+			}
+
+			commitment, next, err := decodeBodyCommitment(buf, offset)
+			if err != nil {
+				return nil, err
+			}
+			fields[OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT] = commitment
+			offset = next
+			continue
+		}
+
+		if flags&field.flag == 0 {
+			// an absent field is encoded as 1 block of zeroes
+			offset += TARGET_ALIGNMENT
+			continue
+		}
+
+		value, next, err := readLengthPrefixed(buf, offset)
+		if err != nil {
+			return nil, field.err
+		}
+		fields[field.name] = string(value)
+		offset = next
+	}
+
+	if flags&OPTIONAL_FIELDS_HEADER_HAS_CANONICAL_BODY != 0 {
+		if err := checkCanonicalRequestBody(fields); err != nil {
+			return nil, err
+		}
+		fields[OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY] = true
+	}
+
+	if flags&OPTIONAL_FIELDS_HEADER_HAS_EXTENSIONS != 0 {
+		var err error
+		offset, err = r.decodeOptionalFieldExtensions(buf, offset, fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if offset != len(buf) {
+		return nil, ErrDecodingOptionalsInvalidEncoding
+	}
+
+	return fields, nil
+}
+
+// OptionalFields contains the fixed optional fields and extension fields. JSON field names of the fixed fields match the Aleo oracle SDK.
+type OptionalFields struct {
+	HtmlResultType     *string `json:"htmlResultType,omitempty"`
+	RequestContentType *string `json:"requestContentType,omitempty"`
+	RequestBody        *string `json:"requestBody,omitempty"`
+	// Commitment of the request body, which is encoded instead of the request body, see CommitRequestBody. Not included in JSON.
+	RequestBodyCommitment *RequestBodyCommitment `json:"-"`
+	// Canonicalizes the JSON request body with CanonicalizeJSON before encoding, see EncodeOptionalFieldMap. Not included in JSON.
+	CanonicalizeRequestBody bool `json:"-"`
+	// Extension fields by name, see EncodeOptionalFieldMap. Not included in JSON, since JSON numbers cannot be decoded to typed values.
+	Extensions OptionalFieldMap `json:"-"`
+}
+
+// Validate checks the rules of the optional fields for the given request method and response format - request content type and request body
+// can be used only with POST requests, HTML result type can be used only with the HTML response format, format-specific extension fields can be used
+// only with their response format, see ResponseFormatOfOptionalField.
+func (f *OptionalFields) Validate(method, responseFormat string) error {
+	if method != "POST" {
+		if f.RequestContentType != nil {
+			return fmt.Errorf("%w: %s", ErrOptionalFieldsPostOnly, OPTIONAL_FIELD_REQUEST_CONTENT_TYPE)
+		}
+		if f.RequestBody != nil || f.RequestBodyCommitment != nil {
+			return fmt.Errorf("%w: %s", ErrOptionalFieldsPostOnly, OPTIONAL_FIELD_REQUEST_BODY)
+		}
+	}
+
+	if f.RequestBody != nil && f.RequestBodyCommitment != nil {
+		return ErrOptionalFieldsBodyAndCommitment
+	}
+
+	if err := validateFormatOptionalFields(f.Extensions, responseFormat); err != nil {
+		return err
+	}
+
+	if f.HtmlResultType != nil {
+		if responseFormat != RESPONSE_FORMAT_HTML {
+			return ErrOptionalFieldsHtmlOnly
+		}
+		if err := validateHtmlResultType(*f.HtmlResultType); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns all fields as a map
+func (f *OptionalFields) fieldMap() (OptionalFieldMap, error) {
+	fields := make(OptionalFieldMap, len(f.Extensions)+3)
+	for name, value := range f.Extensions {
+		if isFixedOptionalField(name) {
+			return nil, fmt.Errorf("%w: %s must be set in its own field", ErrOptionalFieldInvalidValue, name)
+		}
+		fields[name] = value
+	}
+
+	fixed := []struct {
+		name  string
+		value *string
+	}{
+		{name: OPTIONAL_FIELD_HTML_RESULT_TYPE, value: f.HtmlResultType},
+		{name: OPTIONAL_FIELD_REQUEST_CONTENT_TYPE, value: f.RequestContentType},
+		{name: OPTIONAL_FIELD_REQUEST_BODY, value: f.RequestBody},
+	}
+	for _, field := range fixed {
+		if field.value != nil {
+			fields[field.name] = *field.value
+		}
+	}
+
+	if f.RequestBodyCommitment != nil {
+		fields[OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT] = *f.RequestBodyCommitment
+	}
+
+	if f.CanonicalizeRequestBody {
+		fields[OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY] = true
+	}
+
+	return fields, nil
+}
+
+// Encode encodes the optional fields using EncodeOptionalFieldMap. Without extension fields the result is the same as the result of EncodeOptionalFields.
+// The fields are not validated, use Validate to check them.
+func (f *OptionalFields) Encode() ([]byte, error) {
+	fields, err := f.fieldMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return EncodeOptionalFieldMap(fields)
+}
+
+// Decode decodes optional fields created with Encode, EncodeOptionalFields or EncodeOptionalFieldMap into f. Extensions is nil if there are no extension fields.
+func (f *OptionalFields) Decode(buf []byte) error {
+	fields, err := DecodeOptionalFieldMap(buf)
+	if err != nil {
+		return err
+	}
+
+	*f = OptionalFields{}
+
+	for name, value := range fields {
+		switch name {
+		case OPTIONAL_FIELD_HTML_RESULT_TYPE:
+			f.HtmlResultType = fixedFieldValue(value)
+		case OPTIONAL_FIELD_REQUEST_CONTENT_TYPE:
+			f.RequestContentType = fixedFieldValue(value)
+		case OPTIONAL_FIELD_REQUEST_BODY:
+			f.RequestBody = fixedFieldValue(value)
+		case OPTIONAL_FIELD_REQUEST_BODY_COMMITMENT:
+			commitment := value.(RequestBodyCommitment)
+			f.RequestBodyCommitment = &commitment
+		case OPTIONAL_FIELD_CANONICALIZE_REQUEST_BODY:
+			f.CanonicalizeRequestBody = value.(bool)
+		default:
+			if f.Extensions == nil {
+				f.Extensions = make(OptionalFieldMap)
+			}
+			f.Extensions[name] = value
+		}
+	}
+
+	return nil
+}
+
+// returns a pointer to a copy of a decoded fixed field value, which is always a string
+func fixedFieldValue(value any) *string {
+	str := value.(string)
+	return &str
+}
+
+// CommitRequestBody replaces the request body with its commitment, so the body is encoded as its length and SHA-256 digest.
+// If CanonicalizeRequestBody is set, the body is canonicalized with CanonicalizeJSON before computing the commitment.
+// Does nothing if there's no request body.
+func (f *OptionalFields) CommitRequestBody() error {
+	if f.RequestBody == nil {
+		return nil
+	}
+
+	body := *f.RequestBody
+	if f.CanonicalizeRequestBody {
+		canonical, err := CanonicalizeJSON([]byte(body))
+		if err != nil {
+			return err
+		}
+		body = string(canonical)
+	}
+
+	commitment := NewRequestBodyCommitment(body)
+	f.RequestBodyCommitment = &commitment
+	f.RequestBody = nil
+
+	return nil
+}
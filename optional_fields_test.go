@@ -0,0 +1,354 @@
+package aleoOracleEncoding
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeOptionalFieldMap(t *testing.T) {
+	t.Run("fixed fields only", func(t *testing.T) {
+		htmlResultType := HTML_RESULT_TYPE_VALUE
+		body := "body"
+
+		want, err := EncodeOptionalFields(&htmlResultType, nil, &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := EncodeOptionalFieldMap(OptionalFieldMap{
+			OPTIONAL_FIELD_HTML_RESULT_TYPE: htmlResultType,
+			OPTIONAL_FIELD_REQUEST_BODY:     body,
+		})
+		if err != nil {
+			t.Fatalf("EncodeOptionalFieldMap() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("EncodeOptionalFieldMap() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("extension field", func(t *testing.T) {
+		got, err := EncodeOptionalFieldMap(OptionalFieldMap{OPTIONAL_FIELD_TIMEOUT: uint64(1500)})
+		if err != nil {
+			t.Fatalf("EncodeOptionalFieldMap() error = %v", err)
+		}
+
+		want := []byte{
+			OPTIONAL_FIELDS_HEADER_HAS_EXTENSIONS, 0, 0, 0, 0, 0, 0, 0, 6, 0, 0, 0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+			// extension section header - 1 field
+			1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+			// tag 3, 8 bytes
+			OPTIONAL_FIELD_TAG_TIMEOUT, 0, 0, 0, 0, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 0,
+			0xdc, 0x05, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("EncodeOptionalFieldMap() = %v, want %v", got, want)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		fields  OptionalFieldMap
+		wantErr error
+	}{
+		{name: "unknown field", fields: OptionalFieldMap{"unknown": "value"}, wantErr: ErrOptionalFieldUnknown},
+		{name: "invalid extension value", fields: OptionalFieldMap{OPTIONAL_FIELD_TIMEOUT: 1500}, wantErr: ErrOptionalFieldInvalidValue},
+		{name: "invalid fixed value", fields: OptionalFieldMap{OPTIONAL_FIELD_REQUEST_BODY: []byte("body")}, wantErr: ErrOptionalFieldInvalidValue},
+		{name: "unknown HTML result type", fields: OptionalFieldMap{OPTIONAL_FIELD_HTML_RESULT_TYPE: "text"}, wantErr: ErrHtmlResultTypeUnknown},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodeOptionalFieldMap(tt.fields); !errors.Is(err, tt.wantErr) {
+				t.Errorf("EncodeOptionalFieldMap() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeOptionalFieldMap(t *testing.T) {
+	fields := OptionalFieldMap{
+		OPTIONAL_FIELD_HTML_RESULT_TYPE:      HTML_RESULT_TYPE_ELEMENT,
+		OPTIONAL_FIELD_REQUEST_CONTENT_TYPE:  "application/json",
+		OPTIONAL_FIELD_REQUEST_BODY:          `{"query":"price"}`,
+		OPTIONAL_FIELD_QUERY_PARAMETERS:      "a=1&b=2",
+		OPTIONAL_FIELD_FOLLOW_REDIRECTS:      true,
+		OPTIONAL_FIELD_TIMEOUT:               uint64(30000),
+		OPTIONAL_FIELD_TLS_SERVER_NAME:       "api.example.com",
+		OPTIONAL_FIELD_RESPONSE_CONTENT_TYPE: "",
+	}
+
+	encoded, err := EncodeOptionalFieldMap(fields)
+	if err != nil {
+		t.Fatalf("EncodeOptionalFieldMap() error = %v", err)
+	}
+
+	decoded, err := DecodeOptionalFieldMap(encoded)
+	if err != nil {
+		t.Fatalf("DecodeOptionalFieldMap() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, fields) {
+		t.Errorf("DecodeOptionalFieldMap() = %v, want %v", decoded, fields)
+	}
+
+	t.Run("legacy decoder rejects extensions", func(t *testing.T) {
+		if _, _, _, err := DecodeOptionalFields(encoded); err == nil {
+			t.Error("DecodeOptionalFields() expected an error")
+		}
+	})
+
+	t.Run("no fields", func(t *testing.T) {
+		encoded, err := EncodeOptionalFields(nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := DecodeOptionalFieldMap(encoded)
+		if err != nil || len(decoded) != 0 {
+			t.Errorf("DecodeOptionalFieldMap() = %v, %v, want no fields", decoded, err)
+		}
+	})
+
+	// encoded timeout and follow redirects extensions
+	extensions := func() []byte {
+		encoded, err := EncodeOptionalFieldMap(OptionalFieldMap{OPTIONAL_FIELD_FOLLOW_REDIRECTS: false, OPTIONAL_FIELD_TIMEOUT: uint64(1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	}
+	// block indices of the extension fields
+	const followRedirectsBlock, timeoutBlock = 5, 7
+
+	errorTests := []struct {
+		name    string
+		modify  func(buf []byte) []byte
+		wantErr error
+	}{
+		{
+			name:    "unknown flags",
+			modify:  func(buf []byte) []byte { buf[0] |= 0x80; return buf },
+			wantErr: ErrDecodingOptionalsUnknownFlags,
+		},
+		{
+			name:    "block count mismatch",
+			modify:  func(buf []byte) []byte { return append(buf, make([]byte, TARGET_ALIGNMENT)...) },
+			wantErr: ErrDecodingOptionalsCountLengthMismatch,
+		},
+		{
+			name:    "unknown tag",
+			modify:  func(buf []byte) []byte { buf[timeoutBlock*TARGET_ALIGNMENT] = 200; return buf },
+			wantErr: ErrOptionalFieldUnknown,
+		},
+		{
+			name: "unordered tags",
+			modify: func(buf []byte) []byte {
+				buf[timeoutBlock*TARGET_ALIGNMENT] = OPTIONAL_FIELD_TAG_FOLLOW_REDIRECTS
+				return buf
+			},
+			wantErr: ErrDecodingOptionalsUnorderedExtension,
+		},
+		{
+			name:    "invalid bool",
+			modify:  func(buf []byte) []byte { buf[(followRedirectsBlock+1)*TARGET_ALIGNMENT] = 2; return buf },
+			wantErr: ErrDecodingOptionalsInvalidExtension,
+		},
+		{
+			name:    "invalid uint64 length",
+			modify:  func(buf []byte) []byte { buf[timeoutBlock*TARGET_ALIGNMENT+TARGET_ALIGNMENT/2] = 4; return buf },
+			wantErr: ErrDecodingOptionalsInvalidExtension,
+		},
+		{
+			name:    "value length beyond buffer",
+			modify:  func(buf []byte) []byte { buf[timeoutBlock*TARGET_ALIGNMENT+TARGET_ALIGNMENT/2] = 255; return buf },
+			wantErr: ErrDecodingBufferTooShort,
+		},
+		{
+			name:    "non-zero padding",
+			modify:  func(buf []byte) []byte { buf[(followRedirectsBlock+1)*TARGET_ALIGNMENT+1] = 1; return buf },
+			wantErr: ErrDecodingUnexpectedPadding,
+		},
+		{
+			name:    "too many fields",
+			modify:  func(buf []byte) []byte { buf[(followRedirectsBlock-1)*TARGET_ALIGNMENT] = 5; return buf },
+			wantErr: ErrDecodingOptionalsInvalidExtension,
+		},
+		{
+			name:    "missing extension flag",
+			modify:  func(buf []byte) []byte { buf[0] &^= OPTIONAL_FIELDS_HEADER_HAS_EXTENSIONS; return buf },
+			wantErr: ErrDecodingOptionalsInvalidEncoding,
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeOptionalFieldMap(tt.modify(extensions())); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeOptionalFieldMap() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptionalFieldRegistry(t *testing.T) {
+	registry := NewOptionalFieldRegistry()
+	custom := OptionalFieldDefinition{Tag: 1000, Name: "testMaxResponseSize", Type: OPTIONAL_FIELD_TYPE_UINT64}
+	if err := registry.Register(custom); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if definition, ok := registry.Lookup(custom.Name); !ok || definition != custom {
+		t.Errorf("Lookup() = %v, %v, want %v", definition, ok, custom)
+	}
+	if definition, ok := registry.LookupTag(custom.Tag); !ok || definition != custom {
+		t.Errorf("LookupTag() = %v, %v, want %v", definition, ok, custom)
+	}
+
+	fields := OptionalFieldMap{custom.Name: uint64(1 << 20), OPTIONAL_FIELD_TIMEOUT: uint64(10)}
+	encoded, err := registry.EncodeOptionalFieldMap(fields)
+	if err != nil {
+		t.Fatalf("EncodeOptionalFieldMap() error = %v", err)
+	}
+	decoded, err := registry.DecodeOptionalFieldMap(encoded)
+	if err != nil {
+		t.Fatalf("DecodeOptionalFieldMap() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, fields) {
+		t.Errorf("DecodeOptionalFieldMap() = %v, want %v", decoded, fields)
+	}
+
+	// the registered field doesn't leak into the built-in fields and other registries
+	if _, ok := LookupOptionalField(custom.Name); ok {
+		t.Errorf("LookupOptionalField() found %s", custom.Name)
+	}
+	if _, ok := NewOptionalFieldRegistry().LookupTag(custom.Tag); ok {
+		t.Errorf("LookupTag() found tag %d in a new registry", custom.Tag)
+	}
+	if _, err := EncodeOptionalFieldMap(fields); !errors.Is(err, ErrOptionalFieldUnknown) {
+		t.Errorf("EncodeOptionalFieldMap() error = %v, want %v", err, ErrOptionalFieldUnknown)
+	}
+	if _, err := DecodeOptionalFieldMap(encoded); !errors.Is(err, ErrOptionalFieldUnknown) {
+		t.Errorf("DecodeOptionalFieldMap() error = %v, want %v", err, ErrOptionalFieldUnknown)
+	}
+
+	errorTests := []struct {
+		name       string
+		definition OptionalFieldDefinition
+		wantErr    error
+	}{
+		{name: "zero tag", definition: OptionalFieldDefinition{Tag: 0, Name: "testZero"}, wantErr: ErrOptionalFieldInvalidTag},
+		{name: "unknown type", definition: OptionalFieldDefinition{Tag: 1001, Name: "testType", Type: 10}, wantErr: ErrOptionalFieldInvalidType},
+		{name: "duplicate tag", definition: OptionalFieldDefinition{Tag: OPTIONAL_FIELD_TAG_TIMEOUT, Name: "testTimeout"}, wantErr: ErrOptionalFieldAlreadyRegistered},
+		{name: "duplicate name", definition: OptionalFieldDefinition{Tag: 1002, Name: OPTIONAL_FIELD_TIMEOUT}, wantErr: ErrOptionalFieldAlreadyRegistered},
+		{name: "fixed field name", definition: OptionalFieldDefinition{Tag: 1003, Name: OPTIONAL_FIELD_REQUEST_BODY}, wantErr: ErrOptionalFieldAlreadyRegistered},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.Register(tt.definition); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Register() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptionalFieldsValidate(t *testing.T) {
+	element := HTML_RESULT_TYPE_ELEMENT
+	unknown := "text"
+	contentType := "application/json"
+	body := "{}"
+
+	tests := []struct {
+		name           string
+		fields         OptionalFields
+		method         string
+		responseFormat string
+		wantErr        error
+	}{
+		{name: "no fields", method: "GET", responseFormat: RESPONSE_FORMAT_JSON},
+		{name: "POST with body", fields: OptionalFields{RequestContentType: &contentType, RequestBody: &body}, method: "POST", responseFormat: RESPONSE_FORMAT_JSON},
+		{name: "HTML result type", fields: OptionalFields{HtmlResultType: &element}, method: "GET", responseFormat: RESPONSE_FORMAT_HTML},
+		{name: "content type with GET", fields: OptionalFields{RequestContentType: &contentType}, method: "GET", responseFormat: RESPONSE_FORMAT_JSON, wantErr: ErrOptionalFieldsPostOnly},
+		{name: "body with GET", fields: OptionalFields{RequestBody: &body}, method: "GET", responseFormat: RESPONSE_FORMAT_JSON, wantErr: ErrOptionalFieldsPostOnly},
+		{name: "lower case post", fields: OptionalFields{RequestBody: &body}, method: "post", responseFormat: RESPONSE_FORMAT_JSON, wantErr: ErrOptionalFieldsPostOnly},
+		{name: "HTML result type with JSON", fields: OptionalFields{HtmlResultType: &element}, method: "GET", responseFormat: RESPONSE_FORMAT_JSON, wantErr: ErrOptionalFieldsHtmlOnly},
+		{name: "unknown HTML result type", fields: OptionalFields{HtmlResultType: &unknown}, method: "GET", responseFormat: RESPONSE_FORMAT_HTML, wantErr: ErrHtmlResultTypeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fields.Validate(tt.method, tt.responseFormat); !errors.Is(err, tt.wantErr) {
+				t.Errorf("OptionalFields.Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptionalFieldsEncode(t *testing.T) {
+	value := HTML_RESULT_TYPE_VALUE
+	contentType := "text/plain"
+
+	t.Run("same as EncodeOptionalFields", func(t *testing.T) {
+		fields := OptionalFields{HtmlResultType: &value, RequestContentType: &contentType}
+
+		got, err := fields.Encode()
+		if err != nil {
+			t.Fatalf("OptionalFields.Encode() error = %v", err)
+		}
+		want, err := EncodeOptionalFields(&value, &contentType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("OptionalFields.Encode() = %v, want %v", got, want)
+		}
+
+		var decoded OptionalFields
+		if err := decoded.Decode(got); err != nil {
+			t.Fatalf("OptionalFields.Decode() error = %v", err)
+		}
+		if !reflect.DeepEqual(decoded, fields) {
+			t.Errorf("OptionalFields.Decode() = %+v, want %+v", decoded, fields)
+		}
+	})
+
+	t.Run("extensions", func(t *testing.T) {
+		fields := OptionalFields{
+			RequestContentType: &contentType,
+			Extensions:         OptionalFieldMap{OPTIONAL_FIELD_TLS_SERVER_NAME: "example.com", OPTIONAL_FIELD_FOLLOW_REDIRECTS: true},
+		}
+
+		encoded, err := fields.Encode()
+		if err != nil {
+			t.Fatalf("OptionalFields.Encode() error = %v", err)
+		}
+
+		// Decode overwrites all fields
+		decoded := OptionalFields{HtmlResultType: &value}
+		if err := decoded.Decode(encoded); err != nil {
+			t.Fatalf("OptionalFields.Decode() error = %v", err)
+		}
+		if !reflect.DeepEqual(decoded, fields) {
+			t.Errorf("OptionalFields.Decode() = %+v, want %+v", decoded, fields)
+		}
+	})
+
+	t.Run("fixed field in extensions", func(t *testing.T) {
+		fields := OptionalFields{Extensions: OptionalFieldMap{OPTIONAL_FIELD_REQUEST_BODY: "{}"}}
+		if _, err := fields.Encode(); !errors.Is(err, ErrOptionalFieldInvalidValue) {
+			t.Errorf("OptionalFields.Encode() error = %v, want %v", err, ErrOptionalFieldInvalidValue)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		fields := OptionalFields{HtmlResultType: &value, Extensions: OptionalFieldMap{OPTIONAL_FIELD_TIMEOUT: uint64(1)}}
+
+		got, err := json.Marshal(&fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"htmlResultType":"value"}`; string(got) != want {
+			t.Errorf("json.Marshal() = %s, want %s", got, want)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package positionRecorder
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	ErrRollbackUnsupported = errors.New("underlying writer doesn't support truncating")
+	ErrInvalidCheckpoint   = errors.New("checkpoint doesn't belong to the recorder or is ahead of its current state")
+)
+
+// TruncatableWriter is a writer, which can discard data written after a certain point, e.g. bytes.Buffer.
+// Len returns the length of the data in the writer, Truncate discards all but the first n bytes.
+type TruncatableWriter interface {
+	io.Writer
+
+	Len() int
+	Truncate(n int)
+}
+
+// Checkpoint is a saved state of a position recorder and its underlying writer, which can be restored with Rollback.
+type Checkpoint struct {
+	recorder *PositionRecordingProxy
+
+	writerLen  int
+	position   int
+	lastWrite  *PositionInfo
+	historyLen int
+	spans      []Segment
+	openSpans  []int
+}
+
+// Checkpoint saves the current state of the recorder. The underlying writer must implement TruncatableWriter.
+func (r *PositionRecordingProxy) Checkpoint() (*Checkpoint, error) {
+	writer, ok := r.writer.(TruncatableWriter)
+	if !ok {
+		return nil, ErrRollbackUnsupported
+	}
+
+	spans := make([]Segment, len(r.spans))
+	copy(spans, r.spans)
+
+	openSpans := make([]int, len(r.openSpans))
+	copy(openSpans, r.openSpans)
+
+	return &Checkpoint{
+		recorder:   r,
+		writerLen:  writer.Len(),
+		position:   r.position,
+		lastWrite:  r.lastWrite,
+		historyLen: len(r.history),
+		spans:      spans,
+		openSpans:  openSpans,
+	}, nil
+}
+
+// Rollback discards all data written after the checkpoint, including partially written data of failed writes, and restores the
+// positional information, write history and spans to the state at the checkpoint.
+// A checkpoint can be used multiple times, but not after rolling back to an earlier checkpoint.
+func (r *PositionRecordingProxy) Rollback(checkpoint *Checkpoint) error {
+	if checkpoint == nil || checkpoint.recorder != r || checkpoint.position > r.position || checkpoint.historyLen > len(r.history) {
+		return ErrInvalidCheckpoint
+	}
+
+	writer, ok := r.writer.(TruncatableWriter)
+	if !ok {
+		return ErrRollbackUnsupported
+	}
+
+	if checkpoint.writerLen > writer.Len() {
+		return ErrInvalidCheckpoint
+	}
+
+	writer.Truncate(checkpoint.writerLen)
+
+	r.position = checkpoint.position
+	r.lastWrite = checkpoint.lastWrite
+	r.history = r.history[:checkpoint.historyLen]
+
+	r.spans = make([]Segment, len(checkpoint.spans))
+	copy(r.spans, checkpoint.spans)
+
+	r.openSpans = make([]int, len(checkpoint.openSpans))
+	copy(r.openSpans, checkpoint.openSpans)
+
+	return nil
+}
+
+// Transaction runs fn and rolls back all writes made by it if it returns an error. The error returned by fn is returned as is.
+// The underlying writer must implement TruncatableWriter.
+func (r *PositionRecordingProxy) Transaction(fn func() error) error {
+	checkpoint, err := r.Checkpoint()
+	if err != nil {
+		return err
+	}
+
+	if err = fn(); err != nil {
+		if rollbackErr := r.Rollback(checkpoint); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package positionRecorder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// writes only a part of the data and fails
+type failingWriter struct {
+	bytes.Buffer
+	fail bool
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		n, _ := w.Buffer.Write(p[:len(p)/2])
+		return n, errors.New("write failed")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestPositionRecorderCheckpoint(t *testing.T) {
+	oneBlock := bytes.Repeat([]byte{1}, 16)
+	twoBlock := bytes.Repeat([]byte{2}, 32)
+
+	t.Run("unsupported writer", func(t *testing.T) {
+		var b bytes.Buffer
+		// hides Len and Truncate of the buffer
+		rec := NewPositionRecorder(struct{ io.Writer }{&b}, 16)
+
+		if _, err := rec.Checkpoint(); err != ErrRollbackUnsupported {
+			t.Errorf("PositionRecorder.Checkpoint() error = %v, want %v", err, ErrRollbackUnsupported)
+		}
+		if err := rec.Transaction(func() error { return nil }); err != ErrRollbackUnsupported {
+			t.Errorf("PositionRecorder.Transaction() error = %v, want %v", err, ErrRollbackUnsupported)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		var b bytes.Buffer
+		rec := NewPositionRecorder(&b, 16)
+
+		if _, err := rec.WriteLabeled("first", oneBlock); err != nil {
+			t.Fatal(err)
+		}
+		rec.BeginSpan("outer")
+
+		checkpoint, err := rec.Checkpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec.BeginSpan("inner")
+		if _, err := rec.WriteLabeled("second", twoBlock); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rec.EndSpan(); err != nil {
+			t.Fatal(err)
+		}
+		// closes the span, which was opened before the checkpoint
+		if _, err := rec.EndSpan(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := rec.Rollback(checkpoint); err != nil {
+			t.Fatalf("PositionRecorder.Rollback() error = %v", err)
+		}
+
+		if !bytes.Equal(b.Bytes(), oneBlock) {
+			t.Errorf("PositionRecorder.Rollback() buffer = %v, want %v", b.Bytes(), oneBlock)
+		}
+		if lastWrite := rec.GetLastWrite(); lastWrite.Pos != 0 || lastWrite.Len != 1 {
+			t.Errorf("PositionRecorder: expected pos=0 len=1, got pos=%d len=%d", lastWrite.Pos, lastWrite.Len)
+		}
+		if rec.TotalBlocks() != 1 {
+			t.Errorf("PositionRecorder.TotalBlocks() = %d, want 1", rec.TotalBlocks())
+		}
+		if rec.Lookup("second") != nil {
+			t.Error("PositionRecorder.Lookup() returned a write, which was rolled back")
+		}
+		if spans := rec.Spans(); len(spans) != 0 {
+			t.Errorf("PositionRecorder.Spans() = %v, want no closed spans", spans)
+		}
+
+		// the outer span is open again
+		if _, err := rec.Write(twoBlock); err != nil {
+			t.Fatal(err)
+		}
+		outer, err := rec.EndSpan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if outer.Pos != 1 || outer.Len != 2 {
+			t.Errorf("PositionRecorder.EndSpan() = %v, want pos=1 len=2", outer)
+		}
+
+		// the checkpoint can be reused
+		if err := rec.Rollback(checkpoint); err != nil {
+			t.Fatalf("PositionRecorder.Rollback() error = %v", err)
+		}
+		want := []Segment{{Label: "first", PositionInfo: PositionInfo{Pos: 0, Len: 1}}}
+		if segments := rec.Segments(); !reflect.DeepEqual(segments, want) {
+			t.Errorf("PositionRecorder.Segments() = %v, want %v", segments, want)
+		}
+	})
+
+	t.Run("invalid checkpoint", func(t *testing.T) {
+		var b bytes.Buffer
+		rec := NewPositionRecorder(&b, 16)
+		other := NewPositionRecorder(&b, 16)
+
+		first, err := rec.Checkpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rec.Write(oneBlock); err != nil {
+			t.Fatal(err)
+		}
+		second, err := rec.Checkpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := other.Rollback(first); err != ErrInvalidCheckpoint {
+			t.Errorf("PositionRecorder.Rollback() error = %v, want %v", err, ErrInvalidCheckpoint)
+		}
+		if err := rec.Rollback(nil); err != ErrInvalidCheckpoint {
+			t.Errorf("PositionRecorder.Rollback() error = %v, want %v", err, ErrInvalidCheckpoint)
+		}
+		if err := rec.Rollback(first); err != nil {
+			t.Fatal(err)
+		}
+		// the second checkpoint is ahead of the current state
+		if err := rec.Rollback(second); err != ErrInvalidCheckpoint {
+			t.Errorf("PositionRecorder.Rollback() error = %v, want %v", err, ErrInvalidCheckpoint)
+		}
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		var w failingWriter
+		rec := NewPositionRecorder(&w, 16)
+
+		err := rec.Transaction(func() error {
+			_, err := rec.WriteLabeled("committed", oneBlock)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("PositionRecorder.Transaction() error = %v", err)
+		}
+
+		err = rec.Transaction(func() error {
+			if _, err := rec.WriteLabeled("rolled back", oneBlock); err != nil {
+				return err
+			}
+			// the second write fails halfway, leaving partial data in the writer
+			w.fail = true
+			_, err := rec.WriteLabeled("partial", twoBlock)
+			return err
+		})
+		if err == nil {
+			t.Fatal("PositionRecorder.Transaction() = expected an error from a failed write, got nil")
+		}
+
+		if !bytes.Equal(w.Bytes(), oneBlock) {
+			t.Errorf("PositionRecorder.Transaction() buffer = %v, want %v", w.Bytes(), oneBlock)
+		}
+		want := []Segment{{Label: "committed", PositionInfo: PositionInfo{Pos: 0, Len: 1}}}
+		if segments := rec.Segments(); !reflect.DeepEqual(segments, want) {
+			t.Errorf("PositionRecorder.Segments() = %v, want %v", segments, want)
+		}
+		if rec.TotalBlocks() != 1 {
+			t.Errorf("PositionRecorder.TotalBlocks() = %d, want 1", rec.TotalBlocks())
+		}
+	})
+}
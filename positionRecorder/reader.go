@@ -0,0 +1,126 @@
+package positionRecorder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+var (
+	ErrNegativeBlockCount = errors.New("number of blocks to read must not be negative")
+)
+
+// PositionReader is a wrapper around a data stream, which reads whole blocks and records positional information
+// about all read operations. It's a counterpart of PositionRecordingProxy for decoding.
+type PositionReader struct {
+	reader    io.Reader
+	blockSize int
+	lastRead  *PositionInfo
+	history   []Segment
+
+	// index of the block where the next read will start
+	position int
+}
+
+// NewPositionReader creates a new position reader. Block size must be an even number.
+func NewPositionReader(reader io.Reader, blockSize int) *PositionReader {
+	if blockSize%2 != 0 {
+		panic("block size must be an even number")
+	}
+
+	return &PositionReader{
+		reader:    reader,
+		blockSize: blockSize,
+		lastRead:  nil,
+	}
+}
+
+// NewPositionReaderFromBytes creates a new position reader, which reads blocks from buf. Block size must be an even number.
+func NewPositionReaderFromBytes(buf []byte, blockSize int) *PositionReader {
+	return NewPositionReader(bytes.NewReader(buf), blockSize)
+}
+
+// ReadFull reads exactly len(p) bytes from the underlying reader and records successful reads. The length of p must be
+// aligned to the block size. If there is not enough data, ReadFull returns the number of bytes read and io.ErrUnexpectedEOF,
+// or io.EOF if no bytes were read. Incomplete reads are not recorded.
+//
+// PositionReader doesn't implement io.Reader since it only reads whole blocks.
+func (r *PositionReader) ReadFull(p []byte) (n int, err error) {
+	return r.readLabeled("", p)
+}
+
+// ReadBlocks reads n blocks from the underlying reader. See ReadFull.
+func (r *PositionReader) ReadBlocks(n int) ([]byte, error) {
+	return r.ReadLabeled("", n)
+}
+
+// ReadLabeled reads n blocks from the underlying reader and records the label with the positional information of the read operation.
+// The label can be used to find the read operation using Lookup.
+func (r *PositionReader) ReadLabeled(label string, n int) ([]byte, error) {
+	if n < 0 {
+		return nil, ErrNegativeBlockCount
+	}
+
+	buf := make([]byte, n*r.blockSize)
+	if _, err := r.readLabeled(label, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func (r *PositionReader) readLabeled(label string, p []byte) (n int, err error) {
+	length := len(p)
+	if length%r.blockSize != 0 {
+		return 0, ErrDataAlignment
+	}
+
+	n, err = io.ReadFull(r.reader, p)
+	if err != nil {
+		return n, err
+	}
+
+	r.lastRead = &PositionInfo{
+		Pos: r.position,
+		Len: length / r.blockSize,
+	}
+	r.position += r.lastRead.Len
+
+	r.history = append(r.history, Segment{
+		Label:        label,
+		PositionInfo: *r.lastRead,
+	})
+
+	return
+}
+
+// Position returns the index of the block where the next read will start
+func (r *PositionReader) Position() int {
+	return r.position
+}
+
+// GetLastRead returns information about the last complete read operation
+func (r *PositionReader) GetLastRead() *PositionInfo {
+	return r.lastRead
+}
+
+// Segments returns information about all complete read operations in the order they were executed
+func (r *PositionReader) Segments() []Segment {
+	segments := make([]Segment, len(r.history))
+	copy(segments, r.history)
+
+	return segments
+}
+
+// Lookup returns information about the read operation with the given label or nil if there is no such operation.
+// If there are several read operations with the same label, the first one is returned.
+func (r *PositionReader) Lookup(label string) *PositionInfo {
+	for _, segment := range r.history {
+		if segment.Label == label {
+			info := segment.PositionInfo
+			return &info
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package positionRecorder
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestPositionReader(t *testing.T) {
+	t.Run("new reader", func(t *testing.T) {
+		rec := NewPositionReaderFromBytes(nil, 16)
+		if rec == nil {
+			t.Error("NewPositionReaderFromBytes() = want not nil")
+		}
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error(t.Name(), "expected to panic")
+			}
+		}()
+
+		NewPositionReader(bytes.NewReader(nil), 17)
+	})
+
+	t.Run("misaligned", func(t *testing.T) {
+		reader := NewPositionReaderFromBytes(make([]byte, 32), 16)
+
+		if _, err := reader.ReadFull(make([]byte, 17)); err != ErrDataAlignment {
+			t.Errorf("PositionReader.ReadFull() error = %v, want %v", err, ErrDataAlignment)
+		}
+		if _, err := reader.ReadBlocks(-1); err != ErrNegativeBlockCount {
+			t.Errorf("PositionReader.ReadBlocks() error = %v, want %v", err, ErrNegativeBlockCount)
+		}
+		if reader.Position() != 0 || reader.GetLastRead() != nil {
+			t.Error("PositionReader: failed reads must not be recorded")
+		}
+	})
+
+	t.Run("reads", func(t *testing.T) {
+		data := make([]byte, 16*5)
+		for i := range data {
+			data[i] = byte(i / 16)
+		}
+
+		reader := NewPositionReaderFromBytes(data, 16)
+
+		header, err := reader.ReadLabeled("header", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(header, data[:32]) {
+			t.Errorf("PositionReader.ReadLabeled() = %v, want %v", header, data[:32])
+		}
+
+		block := make([]byte, 16)
+		n, err := reader.ReadFull(block)
+		if err != nil || n != 16 {
+			t.Fatalf("PositionReader.ReadFull() n = %d, err = %v", n, err)
+		}
+		if !bytes.Equal(block, data[32:48]) {
+			t.Errorf("PositionReader.ReadFull() = %v, want %v", block, data[32:48])
+		}
+
+		lastRead := reader.GetLastRead()
+		if lastRead.Pos != 2 || lastRead.Len != 1 {
+			t.Errorf("PositionReader: expected pos=2 len=1, got pos=%d len=%d", lastRead.Pos, lastRead.Len)
+		}
+
+		// there are only 2 blocks left
+		if _, err := reader.ReadLabeled("too long", 3); err != io.ErrUnexpectedEOF {
+			t.Errorf("PositionReader.ReadLabeled() error = %v, want %v", err, io.ErrUnexpectedEOF)
+		}
+		if reader.Lookup("too long") != nil || reader.Position() != 3 {
+			t.Error("PositionReader: incomplete reads must not be recorded")
+		}
+	})
+
+	t.Run("segments", func(t *testing.T) {
+		reader := NewPositionReaderFromBytes(make([]byte, 16*4), 16)
+
+		for _, read := range []struct {
+			label  string
+			blocks int
+		}{{"a", 1}, {"b", 0}, {"c", 2}, {"", 1}} {
+			if _, err := reader.ReadLabeled(read.label, read.blocks); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if _, err := reader.ReadBlocks(1); err != io.EOF {
+			t.Errorf("PositionReader.ReadBlocks() error = %v, want %v", err, io.EOF)
+		}
+
+		want := []Segment{
+			{Label: "a", PositionInfo: PositionInfo{Pos: 0, Len: 1}},
+			{Label: "b", PositionInfo: PositionInfo{Pos: 1, Len: 0}},
+			{Label: "c", PositionInfo: PositionInfo{Pos: 1, Len: 2}},
+			{Label: "", PositionInfo: PositionInfo{Pos: 3, Len: 1}},
+		}
+		if segments := reader.Segments(); !reflect.DeepEqual(segments, want) {
+			t.Errorf("PositionReader.Segments() = %v, want %v", segments, want)
+		}
+
+		if info := reader.Lookup("c"); info == nil || *info != want[2].PositionInfo {
+			t.Errorf("PositionReader.Lookup() = %v, want %v", info, want[2].PositionInfo)
+		}
+		if reader.Position() != 4 {
+			t.Errorf("PositionReader.Position() = %d, want 4", reader.Position())
+		}
+	})
+}
@@ -6,7 +6,9 @@ import (
 )
 
 var (
-	ErrDataAlignment = errors.New("data is not aligned to block size")
+	ErrDataAlignment  = errors.New("data is not aligned to block size")
+	ErrNoOpenSpan     = errors.New("there is no open span")
+	ErrInvalidPadding = errors.New("padding length must not be negative or exceed data length")
 )
 
 type PositionInfo struct {
@@ -14,6 +16,28 @@ type PositionInfo struct {
 	Pos int
 	// Number of blocks written in the write operation
 	Len int
+
+	// Byte-level information is optional. It's only recorded by write operations, which know the
+	// padding length, see WritePadded. Use HasByteInfo to check if it's present.
+
+	// Offset of the first written byte in the data stream
+	ByteOffset int `json:",omitempty"`
+	// Number of meaningful bytes at the start of the written data
+	PayloadLen int `json:",omitempty"`
+	// Number of padding bytes following the payload
+	PaddingLen int `json:",omitempty"`
+}
+
+// HasByteInfo returns true if the byte-level information was recorded
+func (info *PositionInfo) HasByteInfo() bool {
+	return info.PayloadLen != 0 || info.PaddingLen != 0
+}
+
+// Segment is positional information about one recorded write operation with an optional label
+type Segment struct {
+	// Label given to the write operation, empty if the data was written without a label
+	Label string
+	PositionInfo
 }
 
 // PositionRecorder records the position and number of blocks written to the underlying data stream.
@@ -23,32 +47,106 @@ type PositionRecorder interface {
 	GetLastWrite() *PositionInfo
 }
 
+// PaddingRecorder is a position recorder, which can also record byte-level information about padded data.
+type PaddingRecorder interface {
+	PositionRecorder
+
+	WritePadded(p []byte, paddingLen int) (PositionInfo, error)
+}
+
 // PositionRecordingProxy is a wrapper around a data stream, which follows io.Writer interface and records
-// positional information about the last write operation.
+// positional information about all write operations.
 type PositionRecordingProxy struct {
 	PositionRecorder
 
 	writer    io.Writer
 	blockSize int
 	lastWrite *PositionInfo
+	history   []Segment
+
+	// index of the block where the recording started
+	offset int
+	// index of the block where the next write will start
+	position int
+
+	// spans in the order they were opened, open spans have negative length
+	spans []Segment
+	// indices of the currently open spans in spans, the innermost span is the last one
+	openSpans []int
+}
+
+// Option configures a position recorder created with NewPositionRecorder
+type Option func(*PositionRecordingProxy)
+
+// WithInitialOffset makes the recorder count positions starting from the given block index instead of 0.
+// Use it when the underlying writer already contains some blocks of data. The offset must not be negative.
+func WithInitialOffset(blocks int) Option {
+	if blocks < 0 {
+		panic("initial offset must not be negative")
+	}
+
+	return func(r *PositionRecordingProxy) {
+		r.offset = blocks
+		r.position = blocks
+	}
 }
 
 // NewPositionRecorder creates a new position recorder. Block size must be an even number.
-func NewPositionRecorder(writer io.Writer, blockSize int) *PositionRecordingProxy {
+func NewPositionRecorder(writer io.Writer, blockSize int, options ...Option) *PositionRecordingProxy {
 	if blockSize%2 != 0 {
 		panic("block size must be an even number")
 	}
 
-	return &PositionRecordingProxy{
+	recorder := &PositionRecordingProxy{
 		writer:    writer,
 		blockSize: blockSize,
 		lastWrite: nil,
 	}
+
+	for _, option := range options {
+		option(recorder)
+	}
+
+	return recorder
 }
 
 // Writes p to the underlying writer and records successful writes. Returned values are io.Writer.Write return values.
 // The information about the last write operation can be obtained using GetLastWrite.
 func (r *PositionRecordingProxy) Write(p []byte) (n int, err error) {
+	return r.WriteLabeled("", p)
+}
+
+// WriteLabeled works like Write and additionally records the label with the positional information of the write operation.
+// The label can be used to find the write operation using Lookup.
+func (r *PositionRecordingProxy) WriteLabeled(label string, p []byte) (n int, err error) {
+	return r.write(label, p, -1)
+}
+
+// WritePadded writes p to the underlying writer like Write, where the last paddingLen bytes of p are padding.
+// The recorded positional information includes byte offset, payload length and padding length, and is returned on success.
+func (r *PositionRecordingProxy) WritePadded(p []byte, paddingLen int) (PositionInfo, error) {
+	return r.WriteLabeledPadded("", p, paddingLen)
+}
+
+// WriteLabeledPadded works like WritePadded and additionally records the label with the positional information of the write operation.
+func (r *PositionRecordingProxy) WriteLabeledPadded(label string, p []byte, paddingLen int) (PositionInfo, error) {
+	if paddingLen < 0 || paddingLen > len(p) {
+		return PositionInfo{}, ErrInvalidPadding
+	}
+
+	n, err := r.write(label, p, paddingLen)
+	if err != nil {
+		return PositionInfo{}, err
+	}
+	if n != len(p) {
+		return PositionInfo{}, io.ErrShortWrite
+	}
+
+	return *r.lastWrite, nil
+}
+
+// writes p and records the write. Byte-level information is recorded if paddingLen is not negative
+func (r *PositionRecordingProxy) write(label string, p []byte, paddingLen int) (n int, err error) {
 	length := len(p)
 	if length%r.blockSize != 0 {
 		return 0, ErrDataAlignment
@@ -62,22 +160,116 @@ func (r *PositionRecordingProxy) Write(p []byte) (n int, err error) {
 		return n, err
 	}
 
-	// get the position where the current write starts
-	lastPos := 0
-	if r.lastWrite != nil {
-		lastPos = r.lastWrite.Pos + r.lastWrite.Len
-	}
-
 	r.lastWrite = &PositionInfo{
-		Pos: lastPos,
+		Pos: r.position,
 		Len: numBlocks,
 	}
+	r.position += numBlocks
+
+	if paddingLen >= 0 {
+		r.lastWrite.ByteOffset = r.lastWrite.Pos * r.blockSize
+		r.lastWrite.PayloadLen = length - paddingLen
+		r.lastWrite.PaddingLen = paddingLen
+	}
+
+	r.history = append(r.history, Segment{
+		Label:        label,
+		PositionInfo: *r.lastWrite,
+	})
 
 	return
 }
 
-// GetLastWrite returns information about the last complete write operation. The information is discarded and replaced
+// BlocksWritten returns the number of blocks written through the recorder. The initial offset is not included.
+func (r *PositionRecordingProxy) BlocksWritten() int {
+	return r.position - r.offset
+}
+
+// TotalBlocks returns the total number of blocks in the underlying data stream including the initial offset,
+// which is also the index of the block where the next write will start.
+func (r *PositionRecordingProxy) TotalBlocks() int {
+	return r.position
+}
+
+// GetLastWrite returns information about the last complete write operation. The information is replaced
 // every time Write was executed successfully
 func (r *PositionRecordingProxy) GetLastWrite() *PositionInfo {
 	return r.lastWrite
 }
+
+// Segments returns information about all complete write operations in the order they were executed
+func (r *PositionRecordingProxy) Segments() []Segment {
+	segments := make([]Segment, len(r.history))
+	copy(segments, r.history)
+
+	return segments
+}
+
+// Lookup returns information about the write operation with the given label or nil if there is no such operation.
+// If there are several write operations with the same label, the first one is returned.
+func (r *PositionRecordingProxy) Lookup(label string) *PositionInfo {
+	for _, segment := range r.history {
+		if segment.Label == label {
+			info := segment.PositionInfo
+			return &info
+		}
+	}
+
+	return nil
+}
+
+// BeginSpan opens a named span, which groups all following write operations until the span is closed with EndSpan.
+// Spans can be nested.
+func (r *PositionRecordingProxy) BeginSpan(name string) {
+	r.openSpans = append(r.openSpans, len(r.spans))
+	r.spans = append(r.spans, Segment{
+		Label: name,
+		PositionInfo: PositionInfo{
+			Pos: r.position,
+			Len: -1,
+		},
+	})
+}
+
+// EndSpan closes the innermost open span and returns the merged positional information of all write operations in it.
+// A span without write operations has zero length and starts at the position of the next write.
+func (r *PositionRecordingProxy) EndSpan() (*PositionInfo, error) {
+	if len(r.openSpans) == 0 {
+		return nil, ErrNoOpenSpan
+	}
+
+	index := r.openSpans[len(r.openSpans)-1]
+	r.openSpans = r.openSpans[:len(r.openSpans)-1]
+
+	span := &r.spans[index]
+	span.Len = r.position - span.Pos
+
+	info := span.PositionInfo
+	return &info, nil
+}
+
+// Spans returns positional information of all closed spans in the order they were opened, so an outer span
+// comes before the spans nested in it. The segment label is the span name.
+func (r *PositionRecordingProxy) Spans() []Segment {
+	spans := make([]Segment, 0, len(r.spans))
+	for _, span := range r.spans {
+		if span.Len >= 0 {
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+// LookupSpan returns positional information of the closed span with the given name or nil if there is no such span.
+// If there are several spans with the same name, the first one is returned.
+func (r *PositionRecordingProxy) LookupSpan(name string) *PositionInfo {
+	for _, span := range r.spans {
+		if span.Label == name && span.Len >= 0 {
+			info := span.PositionInfo
+			return &info
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package positionRecorder
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 )
 
@@ -135,3 +136,287 @@ func TestPositionRecorder(t *testing.T) {
 		})
 	})
 }
+
+func TestPositionRecorderSegments(t *testing.T) {
+	oneBlock := make([]byte, 16)
+	twoBlock := make([]byte, 32)
+
+	t.Run("no writes", func(t *testing.T) {
+		var b bytes.Buffer
+		rec := NewPositionRecorder(&b, 16)
+
+		if segments := rec.Segments(); len(segments) != 0 {
+			t.Errorf("PositionRecorder.Segments() = %v, want empty", segments)
+		}
+		if info := rec.Lookup(""); info != nil {
+			t.Errorf("PositionRecorder.Lookup() = %v, want nil", info)
+		}
+	})
+
+	t.Run("history", func(t *testing.T) {
+		var b bytes.Buffer
+		rec := NewPositionRecorder(&b, 16)
+
+		if _, err := rec.WriteLabeled("header", twoBlock); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rec.Write(oneBlock); err != nil {
+			t.Fatal(err)
+		}
+		// failed writes are not recorded
+		if _, err := rec.WriteLabeled("misaligned", make([]byte, 3)); err == nil {
+			t.Fatal("PositionRecorder.WriteLabeled() = expected err when writing misaligned block, got nil")
+		}
+		if _, err := rec.WriteLabeled("url", twoBlock); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rec.WriteLabeled("url", oneBlock); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []Segment{
+			{Label: "header", PositionInfo: PositionInfo{Pos: 0, Len: 2}},
+			{Label: "", PositionInfo: PositionInfo{Pos: 2, Len: 1}},
+			{Label: "url", PositionInfo: PositionInfo{Pos: 3, Len: 2}},
+			{Label: "url", PositionInfo: PositionInfo{Pos: 5, Len: 1}},
+		}
+
+		segments := rec.Segments()
+		if !reflect.DeepEqual(segments, want) {
+			t.Errorf("PositionRecorder.Segments() = %v, want %v", segments, want)
+		}
+
+		// modifying returned segments doesn't affect the recorder
+		segments[0].Label = "modified"
+		if rec.Segments()[0].Label != "header" {
+			t.Error("PositionRecorder.Segments() returned internal state")
+		}
+
+		info := rec.Lookup("url")
+		if info == nil || info.Pos != 3 || info.Len != 2 {
+			t.Errorf("PositionRecorder.Lookup() = %v, want pos=3 len=2", info)
+		}
+
+		if info := rec.Lookup("misaligned"); info != nil {
+			t.Errorf("PositionRecorder.Lookup() = %v, want nil for a failed write", info)
+		}
+
+		lastWrite := rec.GetLastWrite()
+		if lastWrite.Pos != 5 || lastWrite.Len != 1 {
+			t.Errorf("PositionRecorder: expected pos=5 len=1, got pos=%d len=%d", lastWrite.Pos, lastWrite.Len)
+		}
+	})
+}
+
+func TestPositionRecorderSpans(t *testing.T) {
+	oneBlock := make([]byte, 16)
+	twoBlock := make([]byte, 32)
+
+	t.Run("end without begin", func(t *testing.T) {
+		var b bytes.Buffer
+		rec := NewPositionRecorder(&b, 16)
+
+		if _, err := rec.EndSpan(); err != ErrNoOpenSpan {
+			t.Errorf("PositionRecorder.EndSpan() error = %v, want %v", err, ErrNoOpenSpan)
+		}
+	})
+
+	t.Run("empty span", func(t *testing.T) {
+		var b bytes.Buffer
+		rec := NewPositionRecorder(&b, 16)
+
+		if _, err := rec.Write(oneBlock); err != nil {
+			t.Fatal(err)
+		}
+
+		rec.BeginSpan("empty")
+		info, err := rec.EndSpan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Pos != 1 || info.Len != 0 {
+			t.Errorf("PositionRecorder.EndSpan() = %v, want pos=1 len=0", info)
+		}
+	})
+
+	t.Run("nested spans", func(t *testing.T) {
+		var b bytes.Buffer
+		rec := NewPositionRecorder(&b, 16)
+
+		if _, err := rec.Write(twoBlock); err != nil {
+			t.Fatal(err)
+		}
+
+		rec.BeginSpan("headers")
+		if _, err := rec.Write(oneBlock); err != nil {
+			t.Fatal(err)
+		}
+
+		rec.BeginSpan("entry")
+		if _, err := rec.Write(twoBlock); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rec.Write(oneBlock); err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.LookupSpan("headers") != nil {
+			t.Error("PositionRecorder.LookupSpan() returned a span, which is still open")
+		}
+
+		entry, err := rec.EndSpan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Pos != 3 || entry.Len != 3 {
+			t.Errorf("PositionRecorder.EndSpan() = %v, want pos=3 len=3", entry)
+		}
+
+		if _, err := rec.Write(oneBlock); err != nil {
+			t.Fatal(err)
+		}
+
+		headers, err := rec.EndSpan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if headers.Pos != 2 || headers.Len != 5 {
+			t.Errorf("PositionRecorder.EndSpan() = %v, want pos=2 len=5", headers)
+		}
+
+		// the last write is still the last piece
+		lastWrite := rec.GetLastWrite()
+		if lastWrite.Pos != 6 || lastWrite.Len != 1 {
+			t.Errorf("PositionRecorder: expected pos=6 len=1, got pos=%d len=%d", lastWrite.Pos, lastWrite.Len)
+		}
+
+		want := []Segment{
+			{Label: "headers", PositionInfo: PositionInfo{Pos: 2, Len: 5}},
+			{Label: "entry", PositionInfo: PositionInfo{Pos: 3, Len: 3}},
+		}
+		if spans := rec.Spans(); !reflect.DeepEqual(spans, want) {
+			t.Errorf("PositionRecorder.Spans() = %v, want %v", spans, want)
+		}
+
+		if info := rec.LookupSpan("entry"); info == nil || *info != want[1].PositionInfo {
+			t.Errorf("PositionRecorder.LookupSpan() = %v, want %v", info, want[1].PositionInfo)
+		}
+
+		if _, err := rec.EndSpan(); err != ErrNoOpenSpan {
+			t.Errorf("PositionRecorder.EndSpan() error = %v, want %v", err, ErrNoOpenSpan)
+		}
+	})
+}
+
+func TestPositionRecorderOffset(t *testing.T) {
+	oneBlock := make([]byte, 16)
+	twoBlock := make([]byte, 32)
+
+	t.Run("negative offset", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error(t.Name(), "expected to panic")
+			}
+		}()
+
+		WithInitialOffset(-1)
+	})
+
+	t.Run("no offset", func(t *testing.T) {
+		var b bytes.Buffer
+		rec := NewPositionRecorder(&b, 16)
+
+		if rec.BlocksWritten() != 0 || rec.TotalBlocks() != 0 {
+			t.Errorf("PositionRecorder: expected 0 blocks, got written=%d total=%d", rec.BlocksWritten(), rec.TotalBlocks())
+		}
+
+		if _, err := rec.Write(twoBlock); err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.BlocksWritten() != 2 || rec.TotalBlocks() != 2 {
+			t.Errorf("PositionRecorder: expected 2 blocks, got written=%d total=%d", rec.BlocksWritten(), rec.TotalBlocks())
+		}
+	})
+
+	t.Run("append to existing data", func(t *testing.T) {
+		var b bytes.Buffer
+		// a prefix, which was written without the recorder
+		b.Write(twoBlock)
+		b.Write(oneBlock)
+
+		rec := NewPositionRecorder(&b, 16, WithInitialOffset(b.Len()/16))
+
+		if _, err := rec.Write(oneBlock); err != nil {
+			t.Fatal(err)
+		}
+
+		lastWrite := rec.GetLastWrite()
+		if lastWrite.Pos != 3 || lastWrite.Len != 1 {
+			t.Errorf("PositionRecorder: expected pos=3 len=1, got pos=%d len=%d", lastWrite.Pos, lastWrite.Len)
+		}
+
+		rec.BeginSpan("span")
+		if _, err := rec.WriteLabeled("label", twoBlock); err != nil {
+			t.Fatal(err)
+		}
+		span, err := rec.EndSpan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if span.Pos != 4 || span.Len != 2 {
+			t.Errorf("PositionRecorder.EndSpan() = %v, want pos=4 len=2", span)
+		}
+		if info := rec.Lookup("label"); info == nil || info.Pos != 4 {
+			t.Errorf("PositionRecorder.Lookup() = %v, want pos=4", info)
+		}
+
+		if rec.BlocksWritten() != 3 {
+			t.Errorf("PositionRecorder.BlocksWritten() = %d, want 3", rec.BlocksWritten())
+		}
+		if rec.TotalBlocks() != 6 || rec.TotalBlocks() != b.Len()/16 {
+			t.Errorf("PositionRecorder.TotalBlocks() = %d, want 6", rec.TotalBlocks())
+		}
+	})
+}
+
+func TestPositionRecorderWritePadded(t *testing.T) {
+	var b bytes.Buffer
+	rec := NewPositionRecorder(&b, 16, WithInitialOffset(1))
+
+	if _, err := rec.Write(make([]byte, 16)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.GetLastWrite().HasByteInfo() {
+		t.Error("PositionRecorder.Write() recorded byte-level information")
+	}
+
+	if _, err := rec.WritePadded(make([]byte, 16), 17); err != ErrInvalidPadding {
+		t.Errorf("PositionRecorder.WritePadded() error = %v, want %v", err, ErrInvalidPadding)
+	}
+	if _, err := rec.WritePadded(make([]byte, 16), -1); err != ErrInvalidPadding {
+		t.Errorf("PositionRecorder.WritePadded() error = %v, want %v", err, ErrInvalidPadding)
+	}
+	if _, err := rec.WritePadded(make([]byte, 15), 0); err != ErrDataAlignment {
+		t.Errorf("PositionRecorder.WritePadded() error = %v, want %v", err, ErrDataAlignment)
+	}
+
+	info, err := rec.WriteLabeledPadded("padded", make([]byte, 48), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := PositionInfo{Pos: 2, Len: 3, ByteOffset: 32, PayloadLen: 43, PaddingLen: 5}
+	if info != want {
+		t.Errorf("PositionRecorder.WriteLabeledPadded() = %+v, want %+v", info, want)
+	}
+	if !info.HasByteInfo() {
+		t.Error("PositionInfo.HasByteInfo() = false, want true")
+	}
+	if lookup := rec.Lookup("padded"); lookup == nil || *lookup != want {
+		t.Errorf("PositionRecorder.Lookup() = %+v, want %+v", lookup, want)
+	}
+	if lastWrite := rec.GetLastWrite(); *lastWrite != want {
+		t.Errorf("PositionRecorder.GetLastWrite() = %+v, want %+v", lastWrite, want)
+	}
+}
@@ -0,0 +1,114 @@
+package positionRecorder
+
+import (
+	"io"
+	"sync"
+)
+
+// SyncPositionRecorder is a position recorder, which is safe for concurrent use by multiple goroutines.
+// Every write operation is executed and recorded atomically, use WriteAndRecord to get the position of a write
+// without racing with other writers.
+//
+// Spans are not supported since write operations from different goroutines would end up in the same span.
+type SyncPositionRecorder struct {
+	PositionRecorder
+
+	mu       sync.Mutex
+	recorder *PositionRecordingProxy
+}
+
+// NewSyncPositionRecorder creates a new concurrency-safe position recorder. The arguments are the same as for NewPositionRecorder.
+func NewSyncPositionRecorder(writer io.Writer, blockSize int, options ...Option) *SyncPositionRecorder {
+	return &SyncPositionRecorder{
+		recorder: NewPositionRecorder(writer, blockSize, options...),
+	}
+}
+
+// Write conforms to io.Writer interface. See PositionRecordingProxy.Write.
+func (r *SyncPositionRecorder) Write(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.recorder.Write(p)
+}
+
+// WriteAndRecord writes p to the underlying writer and returns the positional information of this write operation.
+func (r *SyncPositionRecorder) WriteAndRecord(p []byte) (PositionInfo, error) {
+	return r.WriteLabeledAndRecord("", p)
+}
+
+// WriteLabeledAndRecord works like WriteAndRecord and additionally records the label with the positional information of the write operation.
+func (r *SyncPositionRecorder) WriteLabeledAndRecord(label string, p []byte) (PositionInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n, err := r.recorder.WriteLabeled(label, p)
+	if err != nil {
+		return PositionInfo{}, err
+	}
+	if n != len(p) {
+		return PositionInfo{}, io.ErrShortWrite
+	}
+
+	return *r.recorder.GetLastWrite(), nil
+}
+
+// WritePadded writes p to the underlying writer, where the last paddingLen bytes of p are padding, and returns the positional
+// information of this write operation including the byte-level information.
+func (r *SyncPositionRecorder) WritePadded(p []byte, paddingLen int) (PositionInfo, error) {
+	return r.WriteLabeledPadded("", p, paddingLen)
+}
+
+// WriteLabeledPadded works like WritePadded and additionally records the label with the positional information of the write operation.
+func (r *SyncPositionRecorder) WriteLabeledPadded(label string, p []byte, paddingLen int) (PositionInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.recorder.WriteLabeledPadded(label, p, paddingLen)
+}
+
+// GetLastWrite returns a copy of information about the last complete write operation by any goroutine.
+func (r *SyncPositionRecorder) GetLastWrite() *PositionInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lastWrite := r.recorder.GetLastWrite()
+	if lastWrite == nil {
+		return nil
+	}
+
+	info := *lastWrite
+	return &info
+}
+
+// Segments returns information about all complete write operations in the order they were executed
+func (r *SyncPositionRecorder) Segments() []Segment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.recorder.Segments()
+}
+
+// Lookup returns information about the first write operation with the given label or nil if there is no such operation.
+func (r *SyncPositionRecorder) Lookup(label string) *PositionInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.recorder.Lookup(label)
+}
+
+// BlocksWritten returns the number of blocks written through the recorder. The initial offset is not included.
+func (r *SyncPositionRecorder) BlocksWritten() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.recorder.BlocksWritten()
+}
+
+// TotalBlocks returns the total number of blocks in the underlying data stream including the initial offset.
+func (r *SyncPositionRecorder) TotalBlocks() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.recorder.TotalBlocks()
+}
@@ -0,0 +1,98 @@
+package positionRecorder
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSyncPositionRecorder(t *testing.T) {
+	t.Run("misaligned", func(t *testing.T) {
+		var b bytes.Buffer
+		rec := NewSyncPositionRecorder(&b, 16)
+
+		if _, err := rec.WriteAndRecord(make([]byte, 17)); err != ErrDataAlignment {
+			t.Errorf("SyncPositionRecorder.WriteAndRecord() error = %v, want %v", err, ErrDataAlignment)
+		}
+		if rec.GetLastWrite() != nil {
+			t.Error("SyncPositionRecorder.GetLastWrite() = want nil after a failed write")
+		}
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		const writers = 8
+		const writesPerWriter = 50
+
+		var b bytes.Buffer
+		rec := NewSyncPositionRecorder(&b, 16, WithInitialOffset(2))
+		b.Write(make([]byte, 32))
+
+		type record struct {
+			writer int
+			info   PositionInfo
+		}
+		records := make(chan record, writers*writesPerWriter)
+
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(writer int) {
+				defer wg.Done()
+
+				for j := 0; j < writesPerWriter; j++ {
+					// every writer writes blocks filled with its own number, the number of blocks varies
+					data := bytes.Repeat([]byte{byte(writer + 1)}, 16*(j%3+1))
+					info, err := rec.WriteLabeledAndRecord(fmt.Sprint(writer), data)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					records <- record{writer: writer, info: info}
+
+					// readers run concurrently with writers
+					rec.GetLastWrite()
+					rec.Lookup("0")
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(records)
+
+		buf := b.Bytes()
+		totalBlocks := 0
+		for r := range records {
+			totalBlocks += r.info.Len
+
+			// the returned position must point at the data of the writer
+			start := r.info.Pos * 16
+			end := start + r.info.Len*16
+			if end > len(buf) {
+				t.Fatalf("SyncPositionRecorder.WriteAndRecord() = %v, position is outside of the buffer", r.info)
+			}
+			if !bytes.Equal(buf[start:end], bytes.Repeat([]byte{byte(r.writer + 1)}, end-start)) {
+				t.Errorf("SyncPositionRecorder.WriteAndRecord() = %v, position doesn't match the data of writer %d", r.info, r.writer)
+			}
+		}
+
+		if rec.BlocksWritten() != totalBlocks {
+			t.Errorf("SyncPositionRecorder.BlocksWritten() = %d, want %d", rec.BlocksWritten(), totalBlocks)
+		}
+		if rec.TotalBlocks() != totalBlocks+2 || rec.TotalBlocks() != len(buf)/16 {
+			t.Errorf("SyncPositionRecorder.TotalBlocks() = %d, want %d", rec.TotalBlocks(), totalBlocks+2)
+		}
+
+		// segments are recorded in the order of writes, so they must be contiguous
+		segments := rec.Segments()
+		if len(segments) != writers*writesPerWriter {
+			t.Fatalf("SyncPositionRecorder.Segments() has %d segments, want %d", len(segments), writers*writesPerWriter)
+		}
+		nextPos := 2
+		for _, segment := range segments {
+			if segment.Pos != nextPos {
+				t.Fatalf("SyncPositionRecorder.Segments() segment %v is expected to start at %d", segment, nextPos)
+			}
+			nextPos += segment.Len
+		}
+	})
+}
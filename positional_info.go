@@ -0,0 +1,355 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zkportal/aleo-oracle-encoding/positionRecorder"
+)
+
+var (
+	ErrPositionalInfoMissingComponent = errors.New("positional information is missing a component")
+	ErrPositionalInfoMismatch         = errors.New("component position doesn't match the expected position")
+	ErrPositionalInfoNegative         = errors.New("component position or length is negative")
+	ErrPositionalInfoOverlap          = errors.New("component overlaps the previous component")
+	ErrPositionalInfoGap              = errors.New("component doesn't start right after the previous component")
+	ErrPositionalInfoTooLong          = errors.New("component doesn't fit into the Leo struct")
+	ErrPositionalInfoLengthMismatch   = errors.New("component length doesn't match the meta header")
+	ErrPositionalInfoNoMetaHeader     = errors.New("cannot compute positional information without a meta header")
+	ErrPositionalInfoBeyondData       = errors.New("component is located beyond the end of the encoded data")
+	ErrPositionalInfoAmbiguous        = errors.New("cannot determine the length of the attestation data")
+)
+
+const (
+	META_HEADER_BLOCKS = 2 // number of blocks taken by the meta header at the start of the encoded data
+)
+
+// Names of the encoded components. The names match JSON field names of ProofPositionalInfo.
+const (
+	COMPONENT_META_HEADER      = "metaHeader" // not a part of ProofPositionalInfo, always takes the first 2 blocks
+	COMPONENT_DATA             = "data"
+	COMPONENT_TIMESTAMP        = "timestamp"
+	COMPONENT_STATUS_CODE      = "statusCode"
+	COMPONENT_METHOD           = "method"
+	COMPONENT_RESPONSE_FORMAT  = "responseFormat"
+	COMPONENT_URL              = "url"
+	COMPONENT_SELECTOR         = "selector"
+	COMPONENT_ENCODING_OPTIONS = "encodingOptions"
+	COMPONENT_REQUEST_HEADERS  = "requestHeaders"
+	COMPONENT_OPTIONAL_FIELDS  = "optionalFields"
+)
+
+// PositionalComponent is a named reference to the positional information of one of the encoded components.
+type PositionalComponent struct {
+	Name     string
+	Position *positionRecorder.PositionInfo
+}
+
+// Components returns references to the positional information of every component in the canonical order, which is
+// the order of the lengths in the meta header. The returned positions point into p.
+func (p *ProofPositionalInfo) Components() []PositionalComponent {
+	return []PositionalComponent{
+		{Name: COMPONENT_DATA, Position: &p.Data},
+		{Name: COMPONENT_TIMESTAMP, Position: &p.Timestamp},
+		{Name: COMPONENT_STATUS_CODE, Position: &p.StatusCode},
+		{Name: COMPONENT_METHOD, Position: &p.Method},
+		{Name: COMPONENT_RESPONSE_FORMAT, Position: &p.ResponseFormat},
+		{Name: COMPONENT_URL, Position: &p.Url},
+		{Name: COMPONENT_SELECTOR, Position: &p.Selector},
+		{Name: COMPONENT_ENCODING_OPTIONS, Position: &p.EncodingOptions},
+		{Name: COMPONENT_REQUEST_HEADERS, Position: &p.RequestHeaders},
+		{Name: COMPONENT_OPTIONAL_FIELDS, Position: &p.OptionalFields},
+	}
+}
+
+// ProofPositionalInfoFromSegments collects positional information of all components from segments recorded by a position recorder.
+// Every component must be written with a label equal to the component name, e.g. COMPONENT_URL. Segments with other labels are ignored.
+// If a component was written several times, the first write is used.
+func ProofPositionalInfoFromSegments(segments []positionRecorder.Segment) (*ProofPositionalInfo, error) {
+	result := new(ProofPositionalInfo)
+
+	for _, component := range result.Components() {
+		found := false
+		for _, segment := range segments {
+			if segment.Label == component.Name {
+				*component.Position = segment.PositionInfo
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrPositionalInfoMissingComponent, component.Name)
+		}
+	}
+
+	return result, nil
+}
+
+// Verify checks that every component was found at the expected position. The segments can be recorded by a position reader
+// or a position recorder, every component must be labeled with the component name, e.g. COMPONENT_URL.
+// The returned error names the first component, which is missing or found at an unexpected position.
+func (p *ProofPositionalInfo) Verify(segments []positionRecorder.Segment) error {
+	found, err := ProofPositionalInfoFromSegments(segments)
+	if err != nil {
+		return err
+	}
+
+	foundComponents := found.Components()
+	for i, expected := range p.Components() {
+		// byte-level information is not compared since readers don't know the padding
+		if expected.Position.Pos != foundComponents[i].Position.Pos || expected.Position.Len != foundComponents[i].Position.Len {
+			return fmt.Errorf("%w: %s expected at pos=%d len=%d, found at pos=%d len=%d", ErrPositionalInfoMismatch, expected.Name,
+				expected.Position.Pos, expected.Position.Len, foundComponents[i].Position.Pos, foundComponents[i].Position.Len)
+		}
+	}
+
+	return nil
+}
+
+// returns the number of blocks needed to encode the given number of bytes with padding
+func blocksForLength(byteLen int) int {
+	return (byteLen + TARGET_ALIGNMENT - 1) / TARGET_ALIGNMENT
+}
+
+// returns the number of blocks taken by a component of the given length. Every component takes at least 1 block,
+// an empty component is written as 1 block of zeroes, see WriteWithPadding
+func componentBlocks(byteLen int) int {
+	if byteLen == 0 {
+		return 1
+	}
+	return blocksForLength(byteLen)
+}
+
+// returns the encoded byte lengths of all components from the meta header in the canonical order
+func (h *MetaHeader) componentLengths() []int {
+	return []int{
+		h.AttestationDataLen,
+		h.TimestampLen,
+		h.StatusCodeLen,
+		h.MethodLen,
+		h.ResponseFormatLen,
+		h.UrlLen,
+		h.SelectorLen,
+		h.EncodingOptionsLen,
+		h.HeadersLen,
+		h.OptionalFieldsLen,
+	}
+}
+
+// lengths of the fixed-length components written by CreateMetaHeader. Every fixed-length component takes exactly 1 block
+var fixedComponentLengths = map[string]int{
+	COMPONENT_TIMESTAMP:        META_HEADER_TIMESTAMP_LEN,
+	COMPONENT_STATUS_CODE:      META_HEADER_STATUS_CODE_LEN,
+	COMPONENT_RESPONSE_FORMAT:  META_HEADER_RESPONSE_FORMAT_LEN,
+	COMPONENT_ENCODING_OPTIONS: META_HEADER_ENCODING_OPTIONS_LEN,
+}
+
+// returns the name and the length of the first fixed-length component, which length in the meta header differs from the one written by CreateMetaHeader
+func (h *MetaHeader) nonCanonicalFixedLength() (string, int, bool) {
+	lengths := h.componentLengths()
+	for i, component := range new(ProofPositionalInfo).Components() {
+		if fixedLen, ok := fixedComponentLengths[component.Name]; ok && lengths[i] != fixedLen {
+			return component.Name, lengths[i], true
+		}
+	}
+
+	return "", 0, false
+}
+
+// Validate checks the consistency of the positional information. The components must follow the canonical layout - the first
+// component starts right after the meta header and every next component starts right after the previous one, so there are no overlaps or gaps.
+// All components must fit into LEO_MAX_BLOCKS blocks. The timestamp, the status code, the response format and the encoding options must take exactly 1 block.
+//
+// If the header is not nil, the length of every component must match the length in the meta header. The attestation data length in the meta header
+// is the length of the original string, so the attestation data is allowed to take either 1 block (int and float encoding) or as many blocks as
+// the string needs. The lengths of the fixed-length components in the meta header must be the ones written by CreateMetaHeader.
+//
+// The returned error names the first offending component.
+func (p *ProofPositionalInfo) Validate(header *MetaHeader) error {
+	var lengths []int
+	if header != nil {
+		lengths = header.componentLengths()
+	}
+
+	expectedPos := META_HEADER_BLOCKS
+	for i, component := range p.Components() {
+		info := component.Position
+
+		if info.Pos < 0 || info.Len < 0 {
+			return fmt.Errorf("%w: %s", ErrPositionalInfoNegative, component.Name)
+		}
+		if info.Pos < expectedPos {
+			return fmt.Errorf("%w: %s starts at block %d, previous component ends at block %d", ErrPositionalInfoOverlap, component.Name, info.Pos, expectedPos)
+		}
+		if info.Pos > expectedPos {
+			return fmt.Errorf("%w: %s starts at block %d, expected block %d", ErrPositionalInfoGap, component.Name, info.Pos, expectedPos)
+		}
+		if info.Pos+info.Len > LEO_MAX_BLOCKS {
+			return fmt.Errorf("%w: %s ends at block %d", ErrPositionalInfoTooLong, component.Name, info.Pos+info.Len)
+		}
+
+		if fixedLen, ok := fixedComponentLengths[component.Name]; ok {
+			if info.Len != 1 {
+				return fmt.Errorf("%w: %s takes %d blocks, expected 1 block", ErrPositionalInfoLengthMismatch, component.Name, info.Len)
+			}
+			if lengths != nil && lengths[i] != fixedLen {
+				return fmt.Errorf("%w: %s meta header length is %d bytes, expected %d bytes", ErrPositionalInfoLengthMismatch, component.Name, lengths[i], fixedLen)
+			}
+		}
+
+		if lengths != nil {
+			lengthMatches := info.Len == componentBlocks(lengths[i])
+			if component.Name == COMPONENT_DATA {
+				// numbers are always encoded as 1 block
+				lengthMatches = lengthMatches || info.Len == 1
+			}
+
+			if !lengthMatches {
+				return fmt.Errorf("%w: %s takes %d blocks, meta header length is %d bytes", ErrPositionalInfoLengthMismatch, component.Name, info.Len, lengths[i])
+			}
+		}
+
+		expectedPos = info.Pos + info.Len
+	}
+
+	return nil
+}
+
+// returns the number of blocks taken by the encoded attestation data. Without encoding options the data is assumed to be encoded as a string.
+func attestationDataBlocks(stringLen int, options *EncodingOptions) int {
+	if options != nil && options.Value != ENCODING_OPTION_STRING {
+		return 1
+	}
+
+	return componentBlocks(stringLen)
+}
+
+// PositionsFromMetaHeader computes positions of all components from the lengths encoded in the meta header, following the canonical layout -
+// the 2-block meta header followed by the components in the order of their lengths in the meta header.
+//
+// The meta header encodes the length of the original attestation data string, not the encoded length. Numbers are always encoded as 1 block,
+// same as strings of up to 16 characters. If the attestation data is longer than 16 characters, its length depends on the encoding and
+// ErrPositionalInfoAmbiguous is returned - use PositionsFromMetaHeaderWithOptions with known encoding options or LocateComponents with the encoded data.
+func PositionsFromMetaHeader(header *MetaHeader) (ProofPositionalInfo, error) {
+	return PositionsFromMetaHeaderWithOptions(header, nil)
+}
+
+// PositionsFromMetaHeaderWithOptions works like PositionsFromMetaHeader, but uses the encoding options to compute the length of the attestation data.
+// If options is nil, it works exactly like PositionsFromMetaHeader.
+func PositionsFromMetaHeaderWithOptions(header *MetaHeader, options *EncodingOptions) (ProofPositionalInfo, error) {
+	var result ProofPositionalInfo
+	if header == nil {
+		return result, ErrPositionalInfoNoMetaHeader
+	}
+
+	// the string and the number layouts are different
+	if options == nil && attestationDataBlocks(header.AttestationDataLen, nil) != 1 {
+		return result, fmt.Errorf("%w: attestation data length %d without encoding options", ErrPositionalInfoAmbiguous, header.AttestationDataLen)
+	}
+
+	lengths := header.componentLengths()
+
+	pos := META_HEADER_BLOCKS
+	for i, component := range result.Components() {
+		blocks := componentBlocks(lengths[i])
+		if component.Name == COMPONENT_DATA {
+			blocks = attestationDataBlocks(lengths[i], options)
+		}
+
+		component.Position.Pos = pos
+		component.Position.Len = blocks
+		pos += blocks
+	}
+
+	if err := result.Validate(header); err != nil {
+		return ProofPositionalInfo{}, err
+	}
+
+	return result, nil
+}
+
+// returns the blocks of buf at the given position. The position must be within buf
+func componentBytes(buf []byte, info *positionRecorder.PositionInfo) []byte {
+	return buf[info.Pos*TARGET_ALIGNMENT : (info.Pos+info.Len)*TARGET_ALIGNMENT]
+}
+
+// LocateComponents decodes the meta header at the start of the encoded data and computes positions of all components using PositionsFromMetaHeaderWithOptions.
+// If the length of the attestation data is ambiguous, the encoding options are decoded from the data to resolve it. The data may contain trailing blocks
+// after the last component, e.g. zero blocks of a Leo struct.
+func LocateComponents(buf []byte) (*MetaHeader, ProofPositionalInfo, error) {
+	if len(buf) < META_HEADER_BLOCKS*TARGET_ALIGNMENT || len(buf)%TARGET_ALIGNMENT != 0 {
+		return nil, ProofPositionalInfo{}, ErrDecodingBufferTooShort
+	}
+
+	header, err := DecodeMetaHeader(buf[:META_HEADER_BLOCKS*TARGET_ALIGNMENT])
+	if err != nil {
+		return nil, ProofPositionalInfo{}, err
+	}
+
+	// the position of the encoding options depends on the length of the attestation data, which depends on the encoding options.
+	// Numbers always take 1 block, strings take as many blocks as they need. If these lengths are different, try both and pick the layout,
+	// in which the components can be decoded and the encoding options match the assumed encoding.
+	stringOptions := &EncodingOptions{Value: ENCODING_OPTION_STRING}
+	numberOptions := &EncodingOptions{Value: ENCODING_OPTION_INT}
+
+	positions, err := PositionsFromMetaHeaderWithOptions(header, stringOptions)
+	if err != nil {
+		return nil, ProofPositionalInfo{}, err
+	}
+
+	if attestationDataBlocks(header.AttestationDataLen, stringOptions) != attestationDataBlocks(header.AttestationDataLen, numberOptions) {
+		numberPositions, err := PositionsFromMetaHeaderWithOptions(header, numberOptions)
+		if err != nil {
+			return nil, ProofPositionalInfo{}, err
+		}
+
+		stringLayoutErr := checkLayout(buf, &positions, false)
+		numberLayoutErr := checkLayout(buf, &numberPositions, true)
+
+		switch {
+		case stringLayoutErr == nil && numberLayoutErr == nil:
+			return nil, ProofPositionalInfo{}, ErrPositionalInfoAmbiguous
+		case numberLayoutErr == nil:
+			positions = numberPositions
+		case stringLayoutErr != nil:
+			return nil, ProofPositionalInfo{}, stringLayoutErr
+		}
+	}
+
+	for _, component := range positions.Components() {
+		if (component.Position.Pos+component.Position.Len)*TARGET_ALIGNMENT > len(buf) {
+			return nil, ProofPositionalInfo{}, fmt.Errorf("%w: %s", ErrPositionalInfoBeyondData, component.Name)
+		}
+	}
+
+	return header, positions, nil
+}
+
+// checks that the components with a fixed structure can be decoded at the given positions and that the attestation data
+// is encoded as a number or a string as expected
+func checkLayout(buf []byte, positions *ProofPositionalInfo, expectNumber bool) error {
+	for _, component := range positions.Components() {
+		if (component.Position.Pos+component.Position.Len)*TARGET_ALIGNMENT > len(buf) {
+			return fmt.Errorf("%w: %s", ErrPositionalInfoBeyondData, component.Name)
+		}
+	}
+
+	options, err := DecodeEncodingOptions(componentBytes(buf, &positions.EncodingOptions))
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, COMPONENT_ENCODING_OPTIONS)
+	}
+	if (options.Value != ENCODING_OPTION_STRING) != expectNumber {
+		return fmt.Errorf("%w: %s", ErrPositionalInfoMismatch, COMPONENT_ENCODING_OPTIONS)
+	}
+
+	if _, err := DecodeResponseFormat(componentBytes(buf, &positions.ResponseFormat)); err != nil {
+		return fmt.Errorf("%w: %s", err, COMPONENT_RESPONSE_FORMAT)
+	}
+
+	if _, err := DecodeHeaders(componentBytes(buf, &positions.RequestHeaders)); err != nil {
+		return fmt.Errorf("%w: %s", err, COMPONENT_REQUEST_HEADERS)
+	}
+
+	return nil
+}
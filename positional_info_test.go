@@ -0,0 +1,696 @@
+package aleoOracleEncoding
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zkportal/aleo-oracle-encoding/positionRecorder"
+)
+
+func TestProofPositionalInfoFromSegments(t *testing.T) {
+	var b bytes.Buffer
+	rec := positionRecorder.NewPositionRecorder(&b, TARGET_ALIGNMENT)
+
+	writes := []struct {
+		label  string
+		blocks int
+	}{
+		{"metaHeader", 2},
+		{COMPONENT_DATA, 1},
+		{COMPONENT_TIMESTAMP, 1},
+		{COMPONENT_STATUS_CODE, 1},
+		{COMPONENT_METHOD, 1},
+		{COMPONENT_RESPONSE_FORMAT, 1},
+		{COMPONENT_URL, 3},
+		{COMPONENT_SELECTOR, 2},
+		{COMPONENT_ENCODING_OPTIONS, 1},
+		{COMPONENT_REQUEST_HEADERS, 5},
+		{COMPONENT_OPTIONAL_FIELDS, 4},
+	}
+	for _, write := range writes {
+		if _, err := rec.WriteLabeled(write.label, make([]byte, write.blocks*TARGET_ALIGNMENT)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ProofPositionalInfoFromSegments(rec.Segments())
+	if err != nil {
+		t.Fatalf("ProofPositionalInfoFromSegments() error = %v", err)
+	}
+
+	want := &ProofPositionalInfo{
+		Data:            positionRecorder.PositionInfo{Pos: 2, Len: 1},
+		Timestamp:       positionRecorder.PositionInfo{Pos: 3, Len: 1},
+		StatusCode:      positionRecorder.PositionInfo{Pos: 4, Len: 1},
+		Method:          positionRecorder.PositionInfo{Pos: 5, Len: 1},
+		ResponseFormat:  positionRecorder.PositionInfo{Pos: 6, Len: 1},
+		Url:             positionRecorder.PositionInfo{Pos: 7, Len: 3},
+		Selector:        positionRecorder.PositionInfo{Pos: 10, Len: 2},
+		EncodingOptions: positionRecorder.PositionInfo{Pos: 12, Len: 1},
+		RequestHeaders:  positionRecorder.PositionInfo{Pos: 13, Len: 5},
+		OptionalFields:  positionRecorder.PositionInfo{Pos: 18, Len: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ProofPositionalInfoFromSegments() = %+v, want %+v", got, want)
+	}
+
+	_, err = ProofPositionalInfoFromSegments(rec.Segments()[:5])
+	if !errors.Is(err, ErrPositionalInfoMissingComponent) {
+		t.Errorf("ProofPositionalInfoFromSegments() error = %v, want %v", err, ErrPositionalInfoMissingComponent)
+	}
+}
+
+func TestProofPositionalInfo_Verify(t *testing.T) {
+	expected := &ProofPositionalInfo{
+		Data:            positionRecorder.PositionInfo{Pos: 2, Len: 1},
+		Timestamp:       positionRecorder.PositionInfo{Pos: 3, Len: 1},
+		StatusCode:      positionRecorder.PositionInfo{Pos: 4, Len: 1},
+		Method:          positionRecorder.PositionInfo{Pos: 5, Len: 1},
+		ResponseFormat:  positionRecorder.PositionInfo{Pos: 6, Len: 1},
+		Url:             positionRecorder.PositionInfo{Pos: 7, Len: 2},
+		Selector:        positionRecorder.PositionInfo{Pos: 9, Len: 1},
+		EncodingOptions: positionRecorder.PositionInfo{Pos: 10, Len: 1},
+		RequestHeaders:  positionRecorder.PositionInfo{Pos: 11, Len: 1},
+		OptionalFields:  positionRecorder.PositionInfo{Pos: 12, Len: 4},
+	}
+
+	blob := make([]byte, 16*TARGET_ALIGNMENT)
+
+	read := func(lengths map[string]int) []positionRecorder.Segment {
+		reader := positionRecorder.NewPositionReaderFromBytes(blob, TARGET_ALIGNMENT)
+		if _, err := reader.ReadLabeled("metaHeader", 2); err != nil {
+			t.Fatal(err)
+		}
+		for _, component := range expected.Components() {
+			if _, err := reader.ReadLabeled(component.Name, lengths[component.Name]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return reader.Segments()
+	}
+
+	lengths := make(map[string]int)
+	for _, component := range expected.Components() {
+		lengths[component.Name] = component.Position.Len
+	}
+
+	if err := expected.Verify(read(lengths)); err != nil {
+		t.Errorf("ProofPositionalInfo.Verify() error = %v, want nil", err)
+	}
+
+	lengths[COMPONENT_URL] = 1
+	lengths[COMPONENT_OPTIONAL_FIELDS] = 5
+	err := expected.Verify(read(lengths))
+	if !errors.Is(err, ErrPositionalInfoMismatch) || !strings.Contains(err.Error(), COMPONENT_URL) {
+		t.Errorf("ProofPositionalInfo.Verify() error = %v, want %v for %s", err, ErrPositionalInfoMismatch, COMPONENT_URL)
+	}
+
+	err = expected.Verify(nil)
+	if !errors.Is(err, ErrPositionalInfoMissingComponent) {
+		t.Errorf("ProofPositionalInfo.Verify() error = %v, want %v", err, ErrPositionalInfoMissingComponent)
+	}
+}
+
+func TestProofPositionalInfo_Validate(t *testing.T) {
+	// attestation data "12.5", URL of 40 bytes, selector of 20 bytes, 4 blocks of headers and empty optional fields
+	header := &MetaHeader{
+		AttestationDataLen: 4,
+		TimestampLen:       8,
+		StatusCodeLen:      8,
+		MethodLen:          3,
+		ResponseFormatLen:  1,
+		UrlLen:             40,
+		SelectorLen:        20,
+		EncodingOptionsLen: 16,
+		HeadersLen:         64,
+		OptionalFieldsLen:  64,
+	}
+
+	valid := func() *ProofPositionalInfo {
+		return &ProofPositionalInfo{
+			Data:            positionRecorder.PositionInfo{Pos: 2, Len: 1},
+			Timestamp:       positionRecorder.PositionInfo{Pos: 3, Len: 1},
+			StatusCode:      positionRecorder.PositionInfo{Pos: 4, Len: 1},
+			Method:          positionRecorder.PositionInfo{Pos: 5, Len: 1},
+			ResponseFormat:  positionRecorder.PositionInfo{Pos: 6, Len: 1},
+			Url:             positionRecorder.PositionInfo{Pos: 7, Len: 3},
+			Selector:        positionRecorder.PositionInfo{Pos: 10, Len: 2},
+			EncodingOptions: positionRecorder.PositionInfo{Pos: 12, Len: 1},
+			RequestHeaders:  positionRecorder.PositionInfo{Pos: 13, Len: 4},
+			OptionalFields:  positionRecorder.PositionInfo{Pos: 17, Len: 4},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		modify        func(info *ProofPositionalInfo, header *MetaHeader)
+		withHeader    bool
+		wantErr       error
+		wantComponent string
+	}{
+		{
+			name:       "valid",
+			modify:     func(info *ProofPositionalInfo, header *MetaHeader) {},
+			withHeader: true,
+		},
+		{
+			name:   "valid without header",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {},
+		},
+		{
+			name: "valid with long string data",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.AttestationDataLen = 33
+				for _, component := range info.Components() {
+					if component.Name == COMPONENT_DATA {
+						component.Position.Len = 3
+					} else {
+						component.Position.Pos += 2
+					}
+				}
+			},
+			withHeader: true,
+		},
+		{
+			name: "valid with long number data",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.AttestationDataLen = 20
+			},
+			withHeader: true,
+		},
+		{
+			name: "empty string data",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.AttestationDataLen = 0
+			},
+			withHeader: true,
+		},
+		{
+			name: "empty method",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.MethodLen = 0
+			},
+			withHeader: true,
+		},
+		{
+			name: "empty method without a block",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.MethodLen = 0
+				for _, component := range info.Components()[3:] {
+					if component.Name == COMPONENT_METHOD {
+						component.Position.Len = 0
+					} else {
+						component.Position.Pos -= 1
+					}
+				}
+			},
+			withHeader:    true,
+			wantErr:       ErrPositionalInfoLengthMismatch,
+			wantComponent: COMPONENT_METHOD,
+		},
+		{
+			name: "negative length",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				info.Url.Len = -1
+			},
+			wantErr:       ErrPositionalInfoNegative,
+			wantComponent: COMPONENT_URL,
+		},
+		{
+			name: "overlaps meta header",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				info.Data.Pos = 1
+			},
+			wantErr:       ErrPositionalInfoOverlap,
+			wantComponent: COMPONENT_DATA,
+		},
+		{
+			name: "overlap",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				info.Selector.Pos = 9
+			},
+			wantErr:       ErrPositionalInfoOverlap,
+			wantComponent: COMPONENT_SELECTOR,
+		},
+		{
+			name: "gap",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				info.OptionalFields.Pos = 18
+			},
+			wantErr:       ErrPositionalInfoGap,
+			wantComponent: COMPONENT_OPTIONAL_FIELDS,
+		},
+		{
+			name: "swapped components",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				info.Timestamp, info.StatusCode = info.StatusCode, info.Timestamp
+			},
+			wantErr:       ErrPositionalInfoGap,
+			wantComponent: COMPONENT_TIMESTAMP,
+		},
+		{
+			name: "too long",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				info.OptionalFields.Len = 1008
+			},
+			wantErr:       ErrPositionalInfoTooLong,
+			wantComponent: COMPONENT_OPTIONAL_FIELDS,
+		},
+		{
+			name: "timestamp length mismatch",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.TimestampLen = 17
+			},
+			withHeader:    true,
+			wantErr:       ErrPositionalInfoLengthMismatch,
+			wantComponent: COMPONENT_TIMESTAMP,
+		},
+		{
+			name: "long timestamp",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.TimestampLen = 40
+				for _, component := range info.Components()[1:] {
+					if component.Name == COMPONENT_TIMESTAMP {
+						component.Position.Len = 3
+					} else {
+						component.Position.Pos += 2
+					}
+				}
+			},
+			withHeader:    true,
+			wantErr:       ErrPositionalInfoLengthMismatch,
+			wantComponent: COMPONENT_TIMESTAMP,
+		},
+		{
+			name: "long timestamp without header",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				for _, component := range info.Components()[1:] {
+					if component.Name == COMPONENT_TIMESTAMP {
+						component.Position.Len = 3
+					} else {
+						component.Position.Pos += 2
+					}
+				}
+			},
+			wantErr:       ErrPositionalInfoLengthMismatch,
+			wantComponent: COMPONENT_TIMESTAMP,
+		},
+		{
+			name: "empty encoding options",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.EncodingOptionsLen = 0
+				for _, component := range info.Components()[7:] {
+					if component.Name == COMPONENT_ENCODING_OPTIONS {
+						component.Position.Len = 0
+					} else {
+						component.Position.Pos -= 1
+					}
+				}
+			},
+			withHeader:    true,
+			wantErr:       ErrPositionalInfoLengthMismatch,
+			wantComponent: COMPONENT_ENCODING_OPTIONS,
+		},
+		{
+			name: "encoding options length mismatch",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.EncodingOptionsLen = 0
+			},
+			withHeader:    true,
+			wantErr:       ErrPositionalInfoLengthMismatch,
+			wantComponent: COMPONENT_ENCODING_OPTIONS,
+		},
+		{
+			name: "response format length mismatch",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.ResponseFormatLen = 2
+			},
+			withHeader:    true,
+			wantErr:       ErrPositionalInfoLengthMismatch,
+			wantComponent: COMPONENT_RESPONSE_FORMAT,
+		},
+		{
+			name: "headers length mismatch",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				header.HeadersLen = 48
+			},
+			withHeader:    true,
+			wantErr:       ErrPositionalInfoLengthMismatch,
+			wantComponent: COMPONENT_REQUEST_HEADERS,
+		},
+		{
+			name: "data length mismatch",
+			modify: func(info *ProofPositionalInfo, header *MetaHeader) {
+				info.Data.Len = 2
+				for _, component := range info.Components()[1:] {
+					component.Position.Pos += 1
+				}
+			},
+			withHeader:    true,
+			wantErr:       ErrPositionalInfoLengthMismatch,
+			wantComponent: COMPONENT_DATA,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := valid()
+			h := *header
+			tt.modify(info, &h)
+
+			var err error
+			if tt.withHeader {
+				err = info.Validate(&h)
+			} else {
+				err = info.Validate(nil)
+			}
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("ProofPositionalInfo.Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ProofPositionalInfo.Validate() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !strings.Contains(err.Error(), tt.wantComponent) {
+				t.Errorf("ProofPositionalInfo.Validate() error = %v, expected to name %s", err, tt.wantComponent)
+			}
+		})
+	}
+}
+
+type testReport struct {
+	data            string
+	encodingOptions EncodingOptions
+	timestamp       uint64
+	statusCode      uint64
+	method          string
+	responseFormat  string
+	url             string
+	selector        string
+	headers         map[string]string
+	htmlResultType  *string
+	contentType     *string
+	body            *string
+	// encodes the method with EncodeMethod
+	compactMethod bool
+}
+
+// encodes the report in the canonical layout, returns the encoded report and recorded positions
+func encodeTestReport(t *testing.T, report *testReport) ([]byte, *ProofPositionalInfo) {
+	t.Helper()
+
+	data, err := EncodeAttestationData(report.data, &report.encodingOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseFormat, err := EncodeResponseFormat(report.responseFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodingOptions, err := EncodeEncodingOptions(&report.encodingOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := EncodeHeaders(report.headers)
+	optionalFields, err := EncodeOptionalFields(report.htmlResultType, report.contentType, report.body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	method := []byte(report.method)
+	compact := false
+	if report.compactMethod {
+		if method, compact, err = EncodeMethod(report.method); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	metaHeader := make([]byte, TARGET_ALIGNMENT*2)
+	err = CreateMetaHeader(metaHeader, uint16(len(report.data)), uint16(len(method)), uint16(len(report.url)), uint16(len(report.selector)), uint16(len(headers)), uint16(len(optionalFields)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact {
+		if err := SetMetaHeaderFlags(metaHeader, META_HEADER_FLAG_COMPACT_METHOD); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var b bytes.Buffer
+	rec := positionRecorder.NewPositionRecorder(&b, TARGET_ALIGNMENT)
+
+	writes := []struct {
+		label string
+		data  []byte
+	}{
+		{"metaHeader", metaHeader},
+		{COMPONENT_DATA, data},
+		{COMPONENT_TIMESTAMP, NumberToBytes(report.timestamp)},
+		{COMPONENT_STATUS_CODE, NumberToBytes(report.statusCode)},
+		{COMPONENT_METHOD, method},
+		{COMPONENT_RESPONSE_FORMAT, responseFormat},
+		{COMPONENT_URL, []byte(report.url)},
+		{COMPONENT_SELECTOR, []byte(report.selector)},
+		{COMPONENT_ENCODING_OPTIONS, encodingOptions},
+		{COMPONENT_REQUEST_HEADERS, headers},
+		{COMPONENT_OPTIONAL_FIELDS, optionalFields},
+	}
+	for _, write := range writes {
+		padding := getPadding(write.data, TARGET_ALIGNMENT)
+		if len(write.data) == 0 {
+			padding = make([]byte, TARGET_ALIGNMENT)
+		}
+		if _, err := rec.WriteLabeledPadded(write.label, append(write.data, padding...), len(padding)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	positions, err := ProofPositionalInfoFromSegments(rec.Segments())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b.Bytes(), positions
+}
+
+func TestPositionsFromMetaHeader(t *testing.T) {
+	htmlResultType := HTML_RESULT_TYPE_VALUE
+
+	tests := []struct {
+		name        string
+		report      *testReport
+		withOptions bool
+	}{
+		{
+			name: "string data",
+			report: &testReport{
+				data:            "some string, which is longer than a block",
+				encodingOptions: EncodingOptions{Value: ENCODING_OPTION_STRING},
+				method:          "GET",
+				responseFormat:  RESPONSE_FORMAT_HTML,
+				url:             "example.com/some/long/path?query=value",
+				selector:        "/html/body/div/span",
+				headers:         map[string]string{"Accept": "*/*", "User-Agent": "test agent, which has a long name"},
+				htmlResultType:  &htmlResultType,
+			},
+			withOptions: true,
+		},
+		{
+			name: "empty string data",
+			report: &testReport{
+				data:            "",
+				encodingOptions: EncodingOptions{Value: ENCODING_OPTION_STRING},
+				method:          "GET",
+				responseFormat:  RESPONSE_FORMAT_JSON,
+				url:             "example.com",
+				selector:        "",
+				headers:         map[string]string{},
+			},
+		},
+		{
+			name: "short number data",
+			report: &testReport{
+				data:            "12.5",
+				encodingOptions: EncodingOptions{Value: ENCODING_OPTION_FLOAT, Precision: 2},
+				method:          "POST",
+				responseFormat:  RESPONSE_FORMAT_JSON,
+				url:             "example.com/price",
+				selector:        "data.price",
+				headers:         map[string]string{},
+			},
+		},
+		{
+			name: "long number data",
+			report: &testReport{
+				data:            "12345678901234567890",
+				encodingOptions: EncodingOptions{Value: ENCODING_OPTION_INT},
+				method:          "GET",
+				responseFormat:  RESPONSE_FORMAT_JSON,
+				url:             "example.com/price",
+				selector:        "data.price",
+				headers:         map[string]string{},
+			},
+			withOptions: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, want := encodeTestReport(t, tt.report)
+
+			header, err := DecodeMetaHeader(encoded[:TARGET_ALIGNMENT*2])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got ProofPositionalInfo
+			if tt.withOptions {
+				got, err = PositionsFromMetaHeaderWithOptions(header, &tt.report.encodingOptions)
+			} else {
+				got, err = PositionsFromMetaHeader(header)
+			}
+			if err != nil {
+				t.Fatalf("PositionsFromMetaHeader() error = %v", err)
+			}
+
+			if err := got.Verify(segmentsOf(want)); err != nil {
+				t.Errorf("PositionsFromMetaHeader() = %+v, want %+v, error = %v", got, want, err)
+			}
+
+			last := got.OptionalFields
+			if (last.Pos+last.Len)*TARGET_ALIGNMENT != len(encoded) {
+				t.Errorf("PositionsFromMetaHeader() ends at block %d, encoded data has %d blocks", last.Pos+last.Len, len(encoded)/TARGET_ALIGNMENT)
+			}
+		})
+	}
+
+	t.Run("nil header", func(t *testing.T) {
+		if _, err := PositionsFromMetaHeader(nil); !errors.Is(err, ErrPositionalInfoNoMetaHeader) {
+			t.Errorf("PositionsFromMetaHeader() error = %v, want %v", err, ErrPositionalInfoNoMetaHeader)
+		}
+	})
+
+	t.Run("ambiguous attestation data length", func(t *testing.T) {
+		encoded, _ := encodeTestReport(t, &testReport{
+			data:            "12345678901234567890",
+			encodingOptions: EncodingOptions{Value: ENCODING_OPTION_INT},
+			method:          "GET",
+			responseFormat:  RESPONSE_FORMAT_JSON,
+			url:             "example.com/price",
+			selector:        "data.price",
+			headers:         map[string]string{},
+		})
+		header, err := DecodeMetaHeader(encoded[:TARGET_ALIGNMENT*2])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := PositionsFromMetaHeader(header); !errors.Is(err, ErrPositionalInfoAmbiguous) {
+			t.Errorf("PositionsFromMetaHeader() error = %v, want %v", err, ErrPositionalInfoAmbiguous)
+		}
+
+		// the encoded data has the encoding options
+		if _, _, err := LocateComponents(encoded); err != nil {
+			t.Errorf("LocateComponents() error = %v", err)
+		}
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		header := &MetaHeader{UrlLen: 65535, TimestampLen: 8, StatusCodeLen: 8, ResponseFormatLen: 1, EncodingOptionsLen: 16, HeadersLen: 16, OptionalFieldsLen: 64}
+		_, err := PositionsFromMetaHeader(header)
+		if !errors.Is(err, ErrPositionalInfoTooLong) || !strings.Contains(err.Error(), COMPONENT_URL) {
+			t.Errorf("PositionsFromMetaHeader() error = %v, want %v for %s", err, ErrPositionalInfoTooLong, COMPONENT_URL)
+		}
+	})
+}
+
+// converts positional information to labeled segments
+func segmentsOf(info *ProofPositionalInfo) []positionRecorder.Segment {
+	var segments []positionRecorder.Segment
+	for _, component := range info.Components() {
+		segments = append(segments, positionRecorder.Segment{Label: component.Name, PositionInfo: *component.Position})
+	}
+	return segments
+}
+
+func TestLocateComponents(t *testing.T) {
+	htmlResultType := HTML_RESULT_TYPE_ELEMENT
+	body := "request body"
+
+	reports := []*testReport{
+		{
+			data:            "a string",
+			encodingOptions: EncodingOptions{Value: ENCODING_OPTION_STRING},
+			method:          "GET",
+			responseFormat:  RESPONSE_FORMAT_HTML,
+			url:             "example.com",
+			selector:        "/html/body",
+			headers:         map[string]string{"a": "b"},
+			htmlResultType:  &htmlResultType,
+		},
+		{
+			data:            "a long string with exactly 32 by",
+			encodingOptions: EncodingOptions{Value: ENCODING_OPTION_STRING},
+			method:          "POST",
+			responseFormat:  RESPONSE_FORMAT_JSON,
+			url:             "example.com",
+			selector:        "data",
+			headers:         map[string]string{},
+			body:            &body,
+		},
+		{
+			data:            "12345678901234567890",
+			encodingOptions: EncodingOptions{Value: ENCODING_OPTION_INT},
+			method:          "GET",
+			responseFormat:  RESPONSE_FORMAT_JSON,
+			url:             "example.com",
+			selector:        "data",
+			headers:         map[string]string{},
+		},
+		{
+			data:            "1234567.123456789000",
+			encodingOptions: EncodingOptions{Value: ENCODING_OPTION_FLOAT, Precision: 12},
+			method:          "GET",
+			responseFormat:  RESPONSE_FORMAT_JSON,
+			url:             "example.com",
+			selector:        "data",
+			headers:         map[string]string{"a": "b"},
+		},
+	}
+
+	for _, report := range reports {
+		t.Run(report.data, func(t *testing.T) {
+			encoded, want := encodeTestReport(t, report)
+
+			// trailing zero blocks are allowed
+			for _, buf := range [][]byte{encoded, append(encoded, make([]byte, 10*TARGET_ALIGNMENT)...)} {
+				header, got, err := LocateComponents(buf)
+				if err != nil {
+					t.Fatalf("LocateComponents() error = %v", err)
+				}
+				if header.UrlLen != len(report.url) {
+					t.Errorf("LocateComponents() header.UrlLen = %d, want %d", header.UrlLen, len(report.url))
+				}
+				if err := got.Verify(segmentsOf(want)); err != nil {
+					t.Errorf("LocateComponents() = %+v, want %+v, error = %v", got, want, err)
+				}
+			}
+
+			if _, _, err := LocateComponents(encoded[:len(encoded)-TARGET_ALIGNMENT]); err == nil {
+				t.Error("LocateComponents() expected an error for truncated data")
+			}
+		})
+	}
+
+	t.Run("too short", func(t *testing.T) {
+		if _, _, err := LocateComponents(make([]byte, TARGET_ALIGNMENT)); err != ErrDecodingBufferTooShort {
+			t.Errorf("LocateComponents() error = %v, want %v", err, ErrDecodingBufferTooShort)
+		}
+	})
+}
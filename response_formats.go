@@ -0,0 +1,54 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrOptionalFieldsFormatOnly = errors.New("optional field can be used only with its response format")
+	ErrXmlResultTypeUnknown     = errors.New("unknown XML result type")
+)
+
+// XPath result types of the selector for XML responses
+const (
+	XML_RESULT_TYPE_NODE    = "node"    // the selected node is serialized as XML
+	XML_RESULT_TYPE_STRING  = "string"  // the string value of the selected node
+	XML_RESULT_TYPE_NUMBER  = "number"  // the number value of the selected node
+	XML_RESULT_TYPE_BOOLEAN = "boolean" // the boolean value of the selected node
+)
+
+// response formats of the format-specific extension fields
+var formatOptionalFields = map[string]string{
+	OPTIONAL_FIELD_XML_RESULT_TYPE:   RESPONSE_FORMAT_XML,
+	OPTIONAL_FIELD_CSV_ROW:           RESPONSE_FORMAT_CSV,
+	OPTIONAL_FIELD_CSV_COLUMN:        RESPONSE_FORMAT_CSV,
+	OPTIONAL_FIELD_CSV_HAS_HEADER:    RESPONSE_FORMAT_CSV,
+	OPTIONAL_FIELD_GRAPHQL_OPERATION: RESPONSE_FORMAT_GRAPHQL,
+}
+
+// ResponseFormatOfOptionalField returns the response format, which the optional field can be used with.
+// Returns false if the field can be used with any response format.
+func ResponseFormatOfOptionalField(name string) (string, bool) {
+	format, ok := formatOptionalFields[name]
+	return format, ok
+}
+
+// checks that the format-specific extension fields match the response format and have valid values
+func validateFormatOptionalFields(fields OptionalFieldMap, responseFormat string) error {
+	for name := range fields {
+		if format, ok := ResponseFormatOfOptionalField(name); ok && format != responseFormat {
+			return fmt.Errorf("%w: %s requires %s", ErrOptionalFieldsFormatOnly, name, format)
+		}
+	}
+
+	if value, ok := fields[OPTIONAL_FIELD_XML_RESULT_TYPE]; ok {
+		switch value {
+		case XML_RESULT_TYPE_NODE, XML_RESULT_TYPE_STRING, XML_RESULT_TYPE_NUMBER, XML_RESULT_TYPE_BOOLEAN:
+		default:
+			return ErrXmlResultTypeUnknown
+		}
+	}
+
+	return nil
+}
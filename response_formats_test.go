@@ -0,0 +1,54 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFormatOptionalFields(t *testing.T) {
+	tests := []struct {
+		name           string
+		extensions     OptionalFieldMap
+		responseFormat string
+		wantErr        error
+	}{
+		{name: "XML result type", extensions: OptionalFieldMap{OPTIONAL_FIELD_XML_RESULT_TYPE: XML_RESULT_TYPE_STRING}, responseFormat: RESPONSE_FORMAT_XML},
+		{name: "CSV selector", extensions: OptionalFieldMap{OPTIONAL_FIELD_CSV_ROW: uint64(2), OPTIONAL_FIELD_CSV_COLUMN: uint64(0), OPTIONAL_FIELD_CSV_HAS_HEADER: true}, responseFormat: RESPONSE_FORMAT_CSV},
+		{name: "GraphQL operation", extensions: OptionalFieldMap{OPTIONAL_FIELD_GRAPHQL_OPERATION: "GetPrice"}, responseFormat: RESPONSE_FORMAT_GRAPHQL},
+		{name: "generic field with text", extensions: OptionalFieldMap{OPTIONAL_FIELD_TIMEOUT: uint64(1000)}, responseFormat: RESPONSE_FORMAT_TEXT},
+		{name: "XML result type with JSON", extensions: OptionalFieldMap{OPTIONAL_FIELD_XML_RESULT_TYPE: XML_RESULT_TYPE_NODE}, responseFormat: RESPONSE_FORMAT_JSON, wantErr: ErrOptionalFieldsFormatOnly},
+		{name: "CSV row with text", extensions: OptionalFieldMap{OPTIONAL_FIELD_CSV_ROW: uint64(1)}, responseFormat: RESPONSE_FORMAT_TEXT, wantErr: ErrOptionalFieldsFormatOnly},
+		{name: "unknown XML result type", extensions: OptionalFieldMap{OPTIONAL_FIELD_XML_RESULT_TYPE: "nodeset"}, responseFormat: RESPONSE_FORMAT_XML, wantErr: ErrXmlResultTypeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := OptionalFields{Extensions: tt.extensions}
+			if err := fields.Validate("GET", tt.responseFormat); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OptionalFields.Validate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			encoded, err := fields.Encode()
+			if err != nil {
+				t.Fatalf("OptionalFields.Encode() error = %v", err)
+			}
+			var decoded OptionalFields
+			if err := decoded.Decode(encoded); err != nil {
+				t.Fatalf("OptionalFields.Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, fields) {
+				t.Errorf("OptionalFields.Decode() = %+v, want %+v", decoded, fields)
+			}
+		})
+	}
+
+	if format, ok := ResponseFormatOfOptionalField(OPTIONAL_FIELD_CSV_COLUMN); !ok || format != RESPONSE_FORMAT_CSV {
+		t.Errorf("ResponseFormatOfOptionalField() = %q, %v, want %q", format, ok, RESPONSE_FORMAT_CSV)
+	}
+	if _, ok := ResponseFormatOfOptionalField(OPTIONAL_FIELD_TIMEOUT); ok {
+		t.Error("ResponseFormatOfOptionalField() = true for a generic field")
+	}
+}
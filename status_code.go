@@ -0,0 +1,42 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrStatusCodeOutOfRange = errors.New("HTTP status code must be between 100 and 599")
+)
+
+const (
+	STATUS_CODE_MIN = 100
+	STATUS_CODE_MAX = 599
+)
+
+// Encodes an HTTP status code as 1 block. The first 8 little-endian bytes encode the status code, the last 8 bytes are 0.
+// The status code must be between STATUS_CODE_MIN and STATUS_CODE_MAX.
+func EncodeStatusCode(statusCode int) ([]byte, error) {
+	if statusCode < STATUS_CODE_MIN || statusCode > STATUS_CODE_MAX {
+		return nil, fmt.Errorf("%w: %d", ErrStatusCodeOutOfRange, statusCode)
+	}
+
+	buf := make([]byte, TARGET_ALIGNMENT)
+	copy(buf, NumberToBytes(uint64(statusCode)))
+
+	return buf, nil
+}
+
+// Decodes an HTTP status code created with EncodeStatusCode. The buffer must be 1 block.
+func DecodeStatusCode(buf []byte) (int, error) {
+	if len(buf) != TARGET_ALIGNMENT {
+		return 0, ErrDecodingBufferTooShort
+	}
+
+	statusCode := BytesToNumber(buf[:TARGET_ALIGNMENT/2])
+	if statusCode < STATUS_CODE_MIN || statusCode > STATUS_CODE_MAX {
+		return 0, fmt.Errorf("%w: %d", ErrStatusCodeOutOfRange, statusCode)
+	}
+
+	return int(statusCode), nil
+}
@@ -0,0 +1,49 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStatusCode(t *testing.T) {
+	for _, statusCode := range []int{STATUS_CODE_MIN, 200, 404, STATUS_CODE_MAX} {
+		encoded, err := EncodeStatusCode(statusCode)
+		if err != nil {
+			t.Fatalf("EncodeStatusCode(%d) error = %v", statusCode, err)
+		}
+
+		want := append(NumberToBytes(uint64(statusCode)), make([]byte, TARGET_ALIGNMENT/2)...)
+		if !reflect.DeepEqual(encoded, want) {
+			t.Errorf("EncodeStatusCode(%d) = %v, want %v", statusCode, encoded, want)
+		}
+
+		decoded, err := DecodeStatusCode(encoded)
+		if err != nil || decoded != statusCode {
+			t.Errorf("DecodeStatusCode() = %d, %v, want %d", decoded, err, statusCode)
+		}
+	}
+
+	for _, statusCode := range []int{-1, 0, 99, 600, 1000} {
+		if _, err := EncodeStatusCode(statusCode); !errors.Is(err, ErrStatusCodeOutOfRange) {
+			t.Errorf("EncodeStatusCode(%d) error = %v, want %v", statusCode, err, ErrStatusCodeOutOfRange)
+		}
+	}
+
+	decodeErrors := []struct {
+		name    string
+		buf     []byte
+		wantErr error
+	}{
+		{name: "nil", buf: nil, wantErr: ErrDecodingBufferTooShort},
+		{name: "zero", buf: make([]byte, TARGET_ALIGNMENT), wantErr: ErrStatusCodeOutOfRange},
+		{name: "too big", buf: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0}, wantErr: ErrStatusCodeOutOfRange},
+	}
+	for _, tt := range decodeErrors {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeStatusCode(tt.buf); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeStatusCode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
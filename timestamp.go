@@ -0,0 +1,65 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	ErrTimestampUnitUnknown         = errors.New("unknown timestamp unit")
+	ErrEncodingTimestampBeforeEpoch = errors.New("timestamp is before the Unix epoch")
+	ErrDecodingTimestampOutOfRange  = errors.New("encoded timestamp is out of range")
+)
+
+// TimestampUnit defines the unit of an encoded timestamp
+type TimestampUnit int
+
+const (
+	TIMESTAMP_UNIT_SECONDS      TimestampUnit = iota // the timestamp is encoded as seconds since the Unix epoch, the fraction of a second is truncated
+	TIMESTAMP_UNIT_MILLISECONDS                      // the timestamp is encoded as milliseconds since the Unix epoch, the fraction of a millisecond is truncated
+)
+
+// Encodes a timestamp as 1 block. The first 8 little-endian bytes encode the time since the Unix epoch in the given unit, the last 8 bytes are 0.
+// The timestamp cannot be before the Unix epoch.
+func EncodeTimestamp(timestamp time.Time, unit TimestampUnit) ([]byte, error) {
+	var value int64
+	switch unit {
+	case TIMESTAMP_UNIT_SECONDS:
+		value = timestamp.Unix()
+	case TIMESTAMP_UNIT_MILLISECONDS:
+		value = timestamp.UnixMilli()
+	default:
+		return nil, ErrTimestampUnitUnknown
+	}
+
+	if value < 0 {
+		return nil, ErrEncodingTimestampBeforeEpoch
+	}
+
+	buf := make([]byte, TARGET_ALIGNMENT)
+	copy(buf, NumberToBytes(uint64(value)))
+
+	return buf, nil
+}
+
+// Decodes a timestamp created with EncodeTimestamp using the same unit. The buffer must be 1 block. The time is returned in UTC.
+func DecodeTimestamp(buf []byte, unit TimestampUnit) (time.Time, error) {
+	if len(buf) != TARGET_ALIGNMENT {
+		return time.Time{}, ErrDecodingBufferTooShort
+	}
+
+	value := BytesToNumber(buf[:TARGET_ALIGNMENT/2])
+	if value > math.MaxInt64 {
+		return time.Time{}, ErrDecodingTimestampOutOfRange
+	}
+
+	switch unit {
+	case TIMESTAMP_UNIT_SECONDS:
+		return time.Unix(int64(value), 0).UTC(), nil
+	case TIMESTAMP_UNIT_MILLISECONDS:
+		return time.UnixMilli(int64(value)).UTC(), nil
+	default:
+		return time.Time{}, ErrTimestampUnitUnknown
+	}
+}
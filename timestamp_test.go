@@ -0,0 +1,75 @@
+package aleoOracleEncoding
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncodeTimestamp(t *testing.T) {
+	timestamp := time.Date(2023, time.November, 14, 22, 13, 20, 123456789, time.UTC)
+
+	tests := []struct {
+		name    string
+		time    time.Time
+		unit    TimestampUnit
+		want    uint64
+		wantErr error
+	}{
+		{name: "seconds", time: timestamp, unit: TIMESTAMP_UNIT_SECONDS, want: 1700000000},
+		{name: "milliseconds", time: timestamp, unit: TIMESTAMP_UNIT_MILLISECONDS, want: 1700000000123},
+		{name: "other time zone", time: timestamp.In(time.FixedZone("UTC+3", 3*60*60)), unit: TIMESTAMP_UNIT_SECONDS, want: 1700000000},
+		{name: "epoch", time: time.Unix(0, 0), unit: TIMESTAMP_UNIT_MILLISECONDS, want: 0},
+		{name: "before epoch", time: time.Unix(-1, 0), unit: TIMESTAMP_UNIT_SECONDS, wantErr: ErrEncodingTimestampBeforeEpoch},
+		{name: "unknown unit", time: timestamp, unit: TimestampUnit(5), wantErr: ErrTimestampUnitUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeTimestamp(tt.time, tt.unit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EncodeTimestamp() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			want := append(NumberToBytes(tt.want), make([]byte, TARGET_ALIGNMENT/2)...)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("EncodeTimestamp() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeTimestamp(t *testing.T) {
+	block := func(value uint64) []byte {
+		return append(NumberToBytes(value), make([]byte, TARGET_ALIGNMENT/2)...)
+	}
+
+	tests := []struct {
+		name    string
+		buf     []byte
+		unit    TimestampUnit
+		want    time.Time
+		wantErr error
+	}{
+		{name: "seconds", buf: block(1700000000), unit: TIMESTAMP_UNIT_SECONDS, want: time.Date(2023, time.November, 14, 22, 13, 20, 0, time.UTC)},
+		{name: "milliseconds", buf: block(1700000000123), unit: TIMESTAMP_UNIT_MILLISECONDS, want: time.Date(2023, time.November, 14, 22, 13, 20, 123000000, time.UTC)},
+		{name: "short buffer", buf: make([]byte, 8), unit: TIMESTAMP_UNIT_SECONDS, wantErr: ErrDecodingBufferTooShort},
+		{name: "out of range", buf: block(math.MaxInt64 + 1), unit: TIMESTAMP_UNIT_SECONDS, wantErr: ErrDecodingTimestampOutOfRange},
+		{name: "unknown unit", buf: block(0), unit: TimestampUnit(-1), wantErr: ErrTimestampUnitUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeTimestamp(tt.buf, tt.unit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecodeTimestamp() error = %v, want %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) || (tt.wantErr == nil && got.Location() != time.UTC) {
+				t.Errorf("DecodeTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}